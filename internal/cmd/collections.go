@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/client"
+	"github.com/futuretea/localrecall-mcp-server/pkg/core/config"
+	"github.com/futuretea/localrecall-mcp-server/pkg/expiry"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
+	localrecallToolset "github.com/futuretea/localrecall-mcp-server/pkg/toolset/localrecall"
+	"github.com/futuretea/localrecall-mcp-server/pkg/versions"
+)
+
+// newToolsetClient loads the configuration and creates a client for running
+// toolset handlers from the command line. It shares the server's expiry index
+// and version store, so that commands keep expiry schedules and archive
+// overwritten entries like the tools do.
+func newToolsetClient(cfgFile string) (*toolset.LocalRecallClient, *config.StaticConfig, error) {
+	cfg, err := config.LoadConfig(cfgFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var expiryIndex *expiry.Index
+	if cfg.ExpiryIndexPath != "" {
+		if expiryIndex, err = expiry.Load(cfg.ExpiryIndexPath); err != nil {
+			return nil, nil, err
+		}
+	}
+	var versionStore *versions.Store
+	if cfg.VersionStorePath != "" {
+		if versionStore, err = versions.Open(cfg.VersionStorePath, cfg.MaxVersions); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return &toolset.LocalRecallClient{
+		Client:          client.NewClient(cfg.LocalRecallURL, cfg.LocalRecallAPIKey),
+		Contents:        toolset.NewCache[*client.EntryContent](time.Duration(cfg.ContentCacheTTL) * time.Second),
+		Computed:        toolset.NewCache[any](time.Duration(cfg.StatsCacheTTL) * time.Second),
+		Expiry:          expiryIndex,
+		Versions:        versionStore,
+		ScanConcurrency: cfg.ScanConcurrency,
+	}, cfg, nil
+}
+
+// addClientFlags adds the flags needed to reach LocalRecall to a subcommand
+func addClientFlags(cmd *cobra.Command) {
+	cmd.Flags().String("localrecall-url", "http://localhost:8080", "LocalRecall API URL")
+	cmd.Flags().String("localrecall-api-key", "", "LocalRecall API key")
+	cmd.Flags().Int("scan-concurrency", 4, "Maximum number of entries fetched in parallel")
+	cmd.Flags().String("list-output", "json", "Output format (json, yaml, markdown)")
+	cmd.Flags().String("expiry-index-path", "", "Expiry index of the server, to keep entry expiry schedules up to date")
+	cmd.Flags().String("version-store-path", "", "Version store of the server, to archive entries before they are overwritten")
+	cmd.Flags().Int("max-versions", 20, "Archived versions kept per entry (0 = unlimited)")
+}
+
+// newCompareCollectionsCommand creates the compare-collections command
+func newCompareCollectionsCommand(streams IOStreams, cfgFile *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compare-collections <collection-a> <collection-b>",
+		Short: "Compare the entries and sources of two collections",
+		Long: `Compare two LocalRecall collections, for example to verify that a staging
+collection matches production after a migration. Reports entries only in either
+collection, entries present in both with differing content (by SHA-256), and
+differences between their registered external sources.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lrClient, cfg, err := newToolsetClient(*cfgFile)
+			if err != nil {
+				return err
+			}
+			out, err := localrecallToolset.CompareCollectionsHandler(cmd.Context(), lrClient, map[string]interface{}{
+				"collection_a": args[0],
+				"collection_b": args[1],
+				"format":       cfg.ListOutput,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(streams.Out, out)
+			return nil
+		},
+	}
+
+	cmd.SetOut(streams.Out)
+	cmd.SetErr(streams.ErrOut)
+	addClientFlags(cmd)
+
+	return cmd
+}
+
+// newCloneCollectionCommand creates the clone-collection command
+func newCloneCollectionCommand(streams IOStreams, cfgFile *string) *cobra.Command {
+	var noSources, dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "clone-collection <source> <target>",
+		Short: "Copy a collection's entries and sources into a new collection",
+		Long: `Create a new collection and copy every entry and external source of an
+existing collection into it, for example to fork a collection to experiment with.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCloneCollection(cmd.Context(), streams, *cfgFile, args[0], args[1], localrecallToolset.CloneOptions{
+				IncludeSources: !noSources,
+				DryRun:         dryRun,
+			})
+		},
+	}
+
+	cmd.SetOut(streams.Out)
+	cmd.SetErr(streams.ErrOut)
+	addClientFlags(cmd)
+	cmd.Flags().BoolVar(&noSources, "no-sources", false, "Do not register the external sources on the clone")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only report the entries and sources that would be copied")
+
+	return cmd
+}
+
+// newRenameCollectionCommand creates the rename-collection command
+func newRenameCollectionCommand(streams IOStreams, cfgFile *string) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "rename-collection <old-name> <new-name>",
+		Short: "Rename a collection by cloning it and resetting the original",
+		Long: `LocalRecall cannot rename collections. This clones the collection to the new
+name, verifies that every entry was copied, then removes the external sources of
+the original collection and resets it. The original is left untouched if
+verification fails.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCloneCollection(cmd.Context(), streams, *cfgFile, args[0], args[1], localrecallToolset.CloneOptions{
+				IncludeSources: true,
+				Rename:         true,
+				DryRun:         dryRun,
+			})
+		},
+	}
+
+	cmd.SetOut(streams.Out)
+	cmd.SetErr(streams.ErrOut)
+	addClientFlags(cmd)
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only report the entries and sources that would be moved")
+
+	return cmd
+}
+
+// runCloneCollection clones or renames a collection, printing progress to stderr
+func runCloneCollection(ctx context.Context, streams IOStreams, cfgFile, source, target string, opts localrecallToolset.CloneOptions) error {
+	lrClient, cfg, err := newToolsetClient(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	opts.Progress = func(done, total int, entry string) {
+		fmt.Fprintf(streams.ErrOut, "[%d/%d] %s\n", done, total, entry)
+	}
+	result, err := localrecallToolset.CloneCollection(ctx, lrClient, source, target, opts)
+	if err != nil {
+		return err
+	}
+
+	out, err := handler.FormatOutput(result, cfg.ListOutput)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(streams.Out, out)
+	return nil
+}
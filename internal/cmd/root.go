@@ -34,12 +34,29 @@ func bindFlags(cmd *cobra.Command) {
 		"localrecall_url":        "localrecall-url",
 		"localrecall_api_key":    "localrecall-api-key",
 		"localrecall_collection": "localrecall-collection",
+		"allowed_collections":    "allowed-collections",
 		// Output configuration
 		"list_output":    "list-output",
 		"output_filters": "output-filters",
+		"max_tokens":     "max-tokens",
+		// Scanning configuration
+		"scan_concurrency":  "scan-concurrency",
+		"content_cache_ttl": "content-cache-ttl",
+		"stats_cache_ttl":   "stats-cache-ttl",
+		// Expiry configuration
+		"expiry_index_path": "expiry-index-path",
+		"janitor_interval":  "janitor-interval",
+		// Resource configuration
+		"subscription_poll_interval": "subscription-poll-interval",
+		// Versioning configuration
+		"version_store_path": "version-store-path",
+		"max_versions":       "max-versions",
 		// Tool configuration
-		"enabled_tools":  "enabled-tools",
-		"disabled_tools": "disabled-tools",
+		"enabled_tools":        "enabled-tools",
+		"disabled_tools":       "disabled-tools",
+		"tool_timeout":         "tool-timeout",
+		"tool_timeouts":        "tool-timeouts",
+		"require_confirmation": "require-confirmation",
 	}
 
 	for key, flag := range flagBindings {
@@ -86,18 +103,44 @@ for network access.`,
 	cmd.Flags().String("localrecall-url", "http://localhost:8080", "LocalRecall API URL")
 	cmd.Flags().String("localrecall-api-key", "", "LocalRecall API key")
 	cmd.Flags().String("localrecall-collection", "", "Default collection name")
+	cmd.Flags().StringSlice("allowed-collections", []string{}, "Comma-separated list of collections tools may access (empty = all)")
 
 	// Output configuration flags
-	cmd.Flags().String("list-output", "json", "Output format for list operations (json, yaml)")
+	cmd.Flags().String("list-output", "json", "Output format for list operations (json, yaml, markdown)")
 	cmd.Flags().StringSlice("output-filters", []string{}, "Fields to filter from output")
+	cmd.Flags().Int("max-tokens", 0, "Default approximate token budget for search, get_entry_content and diff_entries (0 for unlimited)")
+
+	// Scanning configuration flags
+	cmd.Flags().Int("scan-concurrency", 4, "Maximum number of entries fetched in parallel by scanning tools")
+	cmd.Flags().Int("content-cache-ttl", 60, "Seconds to cache entry contents fetched by scanning tools (0 to disable)")
+	cmd.Flags().Int("stats-cache-ttl", 300, "Seconds to cache collection statistics (0 to disable)")
+
+	// Expiry configuration flags
+	cmd.Flags().String("expiry-index-path", "", "File recording entries scheduled for deletion (empty disables ttl/expires_at)")
+	cmd.Flags().Int("janitor-interval", 60, "Seconds between sweeps deleting expired entries")
+
+	// Resource configuration flags
+	cmd.Flags().Int("subscription-poll-interval", 30, "Seconds between checks of subscribed resources for changes (0 = only after tool calls)")
+
+	// Versioning configuration flags
+	cmd.Flags().String("version-store-path", "", "Directory archiving prior versions of updated entries (empty disables versioning)")
+	cmd.Flags().Int("max-versions", 20, "Archived versions kept per entry (0 = unlimited)")
 
 	// Tool configuration flags
 	cmd.Flags().StringSlice("enabled-tools", []string{}, "Comma-separated list of tools to enable")
 	cmd.Flags().StringSlice("disabled-tools", []string{}, "Comma-separated list of tools to disable")
+	cmd.Flags().Int("tool-timeout", 0, "Default timeout in seconds for tool calls (0 for no timeout)")
+	cmd.Flags().StringToInt("tool-timeouts", map[string]int{}, "Per-tool timeouts in seconds, e.g. search=10,clone_collection=600")
+	cmd.Flags().Bool("require-confirmation", false, "Refuse destructive tool calls the client cannot confirm through elicitation")
 
 	// Add version command
 	cmd.AddCommand(newVersionCommand(streams))
 
+	// Add collection maintenance commands
+	cmd.AddCommand(newCompareCollectionsCommand(streams, &cfgFile))
+	cmd.AddCommand(newCloneCollectionCommand(streams, &cfgFile))
+	cmd.AddCommand(newRenameCollectionCommand(streams, &cfgFile))
+
 	return cmd
 }
 
@@ -0,0 +1,396 @@
+// Package lrtest provides an in-memory LocalRecall API server for tests.
+package lrtest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/client"
+)
+
+// SearchFunc returns the search hits of a query in a collection
+type SearchFunc func(collection, query string, maxResults int) []map[string]interface{}
+
+// Server is a LocalRecall API backed by in-memory collections. Entries are
+// stored as uploaded, and searches match entries containing a query word.
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	collections map[string]map[string]string
+	sources     map[string][]map[string]interface{}
+	requests    map[string]int
+
+	// Search replaces the default word matching (nil = default)
+	Search SearchFunc
+	// FailUpload makes uploads of an entry fail (nil = uploads succeed)
+	FailUpload func(collection, entry string) bool
+	// FailRead makes reads of an entry's content fail (nil = reads succeed)
+	FailRead func(collection, entry string) bool
+}
+
+// NewServer starts a server that is closed when the test ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{
+		collections: make(map[string]map[string]string),
+		sources:     make(map[string][]map[string]interface{}),
+		requests:    make(map[string]int),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/collections", s.listCollections)
+	mux.HandleFunc("POST /api/collections", s.createCollection)
+	mux.HandleFunc("POST /api/collections/{name}/reset", s.resetCollection)
+	mux.HandleFunc("POST /api/collections/{name}/upload", s.upload)
+	mux.HandleFunc("POST /api/collections/{name}/search", s.search)
+	mux.HandleFunc("GET /api/collections/{name}/entries", s.listEntries)
+	mux.HandleFunc("GET /api/collections/{name}/entries/{entry...}", s.getEntry)
+	mux.HandleFunc("DELETE /api/collections/{name}/entry/delete", s.deleteEntry)
+	mux.HandleFunc("GET /api/collections/{name}/sources", s.listSources)
+	mux.HandleFunc("POST /api/collections/{name}/sources", s.registerSource)
+	mux.HandleFunc("DELETE /api/collections/{name}/sources", s.removeSource)
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[r.Method+" "+r.URL.Path]++
+		s.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Client returns a LocalRecall client talking to the server
+func (s *Server) Client() *client.Client {
+	return client.NewClient(s.URL, "")
+}
+
+// AddCollection creates an empty collection if it does not exist
+func (s *Server) AddCollection(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.collections[name]; !ok {
+		s.collections[name] = make(map[string]string)
+	}
+}
+
+// AddEntry stores an entry, creating its collection if needed
+func (s *Server) AddEntry(collection, entry, content string) {
+	s.AddCollection(collection)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.collections[collection][entry] = content
+}
+
+// AddSource registers an external source of a collection
+func (s *Server) AddSource(collection, url string, updateInterval int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources[collection] = append(s.sources[collection], map[string]interface{}{"url": url, "update_interval": updateInterval})
+}
+
+// Entry returns the content of an entry
+func (s *Server) Entry(collection, entry string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, ok := s.collections[collection][entry]
+	return content, ok
+}
+
+// Entries returns the sorted entry names of a collection
+func (s *Server) Entries(collection string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedKeys(s.collections[collection])
+}
+
+// HasCollection reports whether a collection exists
+func (s *Server) HasCollection(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.collections[name]
+	return ok
+}
+
+// Sources returns the source URLs of a collection
+func (s *Server) Sources(collection string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var urls []string
+	for _, src := range s.sources[collection] {
+		urls = append(urls, src["url"].(string))
+	}
+	return urls
+}
+
+// Requests returns how often a method and path were requested, e.g.
+// "GET /api/collections/docs/entries/a.md"
+func (s *Server) Requests(route string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[route]
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// reply writes a successful API response
+func reply(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": data})
+}
+
+// fail writes an API error response
+func fail(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error":   map[string]interface{}{"code": http.StatusText(status), "message": message},
+	})
+}
+
+// decode reads a JSON request body
+func decode(r *http.Request) map[string]interface{} {
+	var body map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	return body
+}
+
+// collection returns the entries of the collection named in the request path
+func (s *Server) collection(w http.ResponseWriter, r *http.Request) (map[string]string, bool) {
+	entries, ok := s.collections[r.PathValue("name")]
+	if !ok {
+		fail(w, http.StatusNotFound, "collection not found")
+	}
+	return entries, ok
+}
+
+func (s *Server) listCollections(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.collections))
+	for name := range s.collections {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	reply(w, map[string]interface{}{"collections": names, "count": len(names)})
+}
+
+func (s *Server) createCollection(w http.ResponseWriter, r *http.Request) {
+	name, _ := decode(r)["name"].(string)
+	s.AddCollection(name)
+	reply(w, map[string]interface{}{"name": name, "created_at": "2026-01-01T00:00:00Z"})
+}
+
+func (s *Server) resetCollection(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.collection(w, r); !ok {
+		return
+	}
+	s.collections[r.PathValue("name")] = make(map[string]string)
+	delete(s.sources, r.PathValue("name"))
+	reply(w, map[string]interface{}{"reset_at": "2026-01-01T00:00:00Z"})
+}
+
+func (s *Server) upload(w http.ResponseWriter, r *http.Request) {
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		fail(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		fail(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, ok := s.collection(w, r)
+	if !ok {
+		return
+	}
+	if s.FailUpload != nil && s.FailUpload(r.PathValue("name"), header.Filename) {
+		fail(w, http.StatusInternalServerError, "upload failed")
+		return
+	}
+	entries[header.Filename] = string(data)
+	reply(w, map[string]interface{}{"filename": header.Filename, "created_at": "2026-01-01T00:00:00Z"})
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	body := decode(r)
+	query, _ := body["query"].(string)
+	maxResults := 5
+	if n, ok := body["max_results"].(float64); ok {
+		maxResults = int(n)
+	}
+
+	s.mu.Lock()
+	entries, ok := s.collection(w, r)
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	var hits []map[string]interface{}
+	if s.Search != nil {
+		s.mu.Unlock()
+		hits = s.Search(r.PathValue("name"), query, maxResults)
+	} else {
+		hits = matchWords(entries, query, maxResults)
+		s.mu.Unlock()
+	}
+	if hits == nil {
+		hits = []map[string]interface{}{}
+	}
+	reply(w, map[string]interface{}{"query": query, "max_results": maxResults, "results": hits, "count": len(hits)})
+}
+
+// matchWords returns the entries containing a word of the query, scored by
+// the share of query words they contain. Callers must hold s.mu.
+func matchWords(entries map[string]string, query string, maxResults int) []map[string]interface{} {
+	words := strings.Fields(strings.ToLower(query))
+	var hits []map[string]interface{}
+	for _, entry := range sortedKeys(entries) {
+		content := strings.ToLower(entries[entry])
+		matched := 0
+		for _, word := range words {
+			if strings.Contains(content, word) {
+				matched++
+			}
+		}
+		if matched == 0 {
+			continue
+		}
+		hits = append(hits, Hit(entry+"#1", entries[entry], float64(matched)/float64(len(words)), entry))
+	}
+	slices.SortStableFunc(hits, func(a, b map[string]interface{}) int {
+		sa, sb := a["Similarity"].(float64), b["Similarity"].(float64)
+		switch {
+		case sa > sb:
+			return -1
+		case sa < sb:
+			return 1
+		}
+		return 0
+	})
+	if len(hits) > maxResults {
+		hits = hits[:maxResults]
+	}
+	return hits
+}
+
+// Hit builds a search hit in the shape returned by LocalRecall
+func Hit(id, content string, similarity float64, source string) map[string]interface{} {
+	return map[string]interface{}{
+		"ID":         id,
+		"Content":    content,
+		"Similarity": similarity,
+		"Metadata":   map[string]interface{}{"source": source},
+	}
+}
+
+func (s *Server) listEntries(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, ok := s.collection(w, r)
+	if !ok {
+		return
+	}
+	names := sortedKeys(entries)
+	reply(w, map[string]interface{}{"entries": names, "count": len(names)})
+}
+
+func (s *Server) getEntry(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, ok := s.collection(w, r)
+	if !ok {
+		return
+	}
+	name, entry := r.PathValue("name"), r.PathValue("entry")
+	content, ok := entries[entry]
+	if !ok {
+		fail(w, http.StatusNotFound, "entry not found")
+		return
+	}
+	if s.FailRead != nil && s.FailRead(name, entry) {
+		fail(w, http.StatusInternalServerError, "read failed")
+		return
+	}
+	reply(w, map[string]interface{}{"collection": name, "entry": entry, "content": content, "chunk_count": 1})
+}
+
+func (s *Server) deleteEntry(w http.ResponseWriter, r *http.Request) {
+	entry, _ := decode(r)["entry"].(string)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, ok := s.collection(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := entries[entry]; !ok {
+		fail(w, http.StatusNotFound, "entry not found")
+		return
+	}
+	delete(entries, entry)
+	remaining := sortedKeys(entries)
+	reply(w, map[string]interface{}{"remaining_entries": remaining, "entry_count": len(remaining)})
+}
+
+func (s *Server) listSources(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.collection(w, r); !ok {
+		return
+	}
+	name := r.PathValue("name")
+	sources := s.sources[name]
+	if sources == nil {
+		sources = []map[string]interface{}{}
+	}
+	reply(w, map[string]interface{}{"collection": name, "sources": sources, "count": len(sources)})
+}
+
+func (s *Server) registerSource(w http.ResponseWriter, r *http.Request) {
+	body := decode(r)
+	url, _ := body["url"].(string)
+	interval, _ := body["update_interval"].(float64)
+	s.mu.Lock()
+	_, ok := s.collection(w, r)
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+	s.AddSource(r.PathValue("name"), url, int(interval))
+	reply(w, map[string]interface{}{"collection": r.PathValue("name"), "url": url, "update_interval": int(interval)})
+}
+
+func (s *Server) removeSource(w http.ResponseWriter, r *http.Request) {
+	url, _ := decode(r)["url"].(string)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.collection(w, r); !ok {
+		return
+	}
+	name := r.PathValue("name")
+	s.sources[name] = slices.DeleteFunc(s.sources[name], func(src map[string]interface{}) bool { return src["url"] == url })
+	reply(w, map[string]interface{}{})
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -17,17 +18,43 @@ type StaticConfig struct {
 	LogLevel int `mapstructure:"log_level"`
 
 	// LocalRecall configuration
-	LocalRecallURL        string `mapstructure:"localrecall_url"`
-	LocalRecallAPIKey     string `mapstructure:"localrecall_api_key"`
-	LocalRecallCollection string `mapstructure:"localrecall_collection"`
+	LocalRecallURL        string   `mapstructure:"localrecall_url"`
+	LocalRecallAPIKey     string   `mapstructure:"localrecall_api_key"`
+	LocalRecallCollection string   `mapstructure:"localrecall_collection"`
+	AllowedCollections    []string `mapstructure:"allowed_collections"`
 
 	// Output configuration
 	ListOutput    string   `mapstructure:"list_output"`
 	OutputFilters []string `mapstructure:"output_filters"`
+	MaxTokens     int      `mapstructure:"max_tokens"`
+
+	// Scanning configuration
+	ScanConcurrency int `mapstructure:"scan_concurrency"`
+	ContentCacheTTL int `mapstructure:"content_cache_ttl"`
+	StatsCacheTTL   int `mapstructure:"stats_cache_ttl"`
+
+	// Expiry configuration
+	ExpiryIndexPath string `mapstructure:"expiry_index_path"`
+	JanitorInterval int    `mapstructure:"janitor_interval"`
+
+	// Resource configuration
+	SubscriptionPollInterval int `mapstructure:"subscription_poll_interval"`
+
+	// Versioning configuration
+	VersionStorePath string `mapstructure:"version_store_path"`
+	MaxVersions      int    `mapstructure:"max_versions"`
 
 	// Tool configuration
-	EnabledTools  []string `mapstructure:"enabled_tools"`
-	DisabledTools []string `mapstructure:"disabled_tools"`
+	EnabledTools  []string       `mapstructure:"enabled_tools"`
+	DisabledTools []string       `mapstructure:"disabled_tools"`
+	ToolTimeout   int            `mapstructure:"tool_timeout"`
+	ToolTimeouts  map[string]int `mapstructure:"tool_timeouts"`
+
+	// RequireConfirmation refuses destructive calls the client cannot confirm
+	RequireConfirmation bool `mapstructure:"require_confirmation"`
+
+	// Prompt configuration
+	Prompts []PromptTemplate `mapstructure:"prompts"`
 }
 
 // Validate validates the configuration
@@ -44,12 +71,64 @@ func (c *StaticConfig) Validate() error {
 
 	// Validate list output
 	validOutputs := map[string]bool{
-		"table": true,
-		"yaml":  true,
-		"json":  true,
+		"table":    true,
+		"yaml":     true,
+		"json":     true,
+		"markdown": true,
 	}
 	if !validOutputs[strings.ToLower(c.ListOutput)] {
-		return fmt.Errorf("list_output must be one of: table, yaml, json, got %s", c.ListOutput)
+		return fmt.Errorf("list_output must be one of: table, yaml, json, markdown, got %s", c.ListOutput)
+	}
+
+	// Validate max tokens
+	if c.MaxTokens < 0 {
+		return fmt.Errorf("max_tokens must be 0 (unlimited) or positive, got %d", c.MaxTokens)
+	}
+
+	// Validate scanning configuration
+	if c.ScanConcurrency < 0 {
+		return fmt.Errorf("scan_concurrency must be 0 (default) or positive, got %d", c.ScanConcurrency)
+	}
+	if c.ContentCacheTTL < 0 {
+		return fmt.Errorf("content_cache_ttl must be 0 (disabled) or positive, got %d", c.ContentCacheTTL)
+	}
+	if c.StatsCacheTTL < 0 {
+		return fmt.Errorf("stats_cache_ttl must be 0 (disabled) or positive, got %d", c.StatsCacheTTL)
+	}
+
+	// Validate expiry configuration
+	if c.ExpiryIndexPath != "" && c.JanitorInterval <= 0 {
+		return fmt.Errorf("janitor_interval must be positive when expiry_index_path is set, got %d", c.JanitorInterval)
+	}
+
+	// Validate resource configuration
+	if c.SubscriptionPollInterval < 0 {
+		return fmt.Errorf("subscription_poll_interval must be 0 (disabled) or positive, got %d", c.SubscriptionPollInterval)
+	}
+
+	// Validate collection allowlist
+	if c.LocalRecallCollection != "" && len(c.AllowedCollections) > 0 && !slices.Contains(c.AllowedCollections, c.LocalRecallCollection) {
+		return fmt.Errorf("localrecall_collection %q is not in allowed_collections", c.LocalRecallCollection)
+	}
+
+	// Validate versioning configuration
+	if c.MaxVersions < 0 {
+		return fmt.Errorf("max_versions must be non-negative, got %d", c.MaxVersions)
+	}
+
+	// Validate tool timeouts
+	if c.ToolTimeout < 0 {
+		return fmt.Errorf("tool_timeout must be non-negative, got %d", c.ToolTimeout)
+	}
+	for tool, timeout := range c.ToolTimeouts {
+		if timeout < 0 {
+			return fmt.Errorf("tool_timeouts.%s must be non-negative, got %d", tool, timeout)
+		}
+	}
+
+	// Validate prompt templates
+	if err := validatePrompts(c.Prompts); err != nil {
+		return err
 	}
 
 	// Validate LocalRecall URL
@@ -73,6 +152,12 @@ func LoadConfig(configPath string) (*StaticConfig, error) {
 	v.SetDefault("log_level", 5)
 	v.SetDefault("localrecall_url", "http://localhost:8080")
 	v.SetDefault("list_output", "json")
+	v.SetDefault("scan_concurrency", 4)
+	v.SetDefault("content_cache_ttl", 60)
+	v.SetDefault("stats_cache_ttl", 300)
+	v.SetDefault("janitor_interval", 60)
+	v.SetDefault("max_versions", 20)
+	v.SetDefault("subscription_poll_interval", 30)
 
 	// Set configuration file if provided
 	if configPath != "" {
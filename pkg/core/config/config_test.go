@@ -0,0 +1,35 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate_ToolTimeouts(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeout  int
+		timeouts map[string]int
+		want     string
+	}{
+		{name: "defaults", timeout: 0, timeouts: nil},
+		{name: "positive", timeout: 30, timeouts: map[string]int{"search": 10, "clone_collection": 0}},
+		{name: "negative default", timeout: -1, want: "tool_timeout must be non-negative, got -1"},
+		{name: "negative per tool", timeouts: map[string]int{"search": -5}, want: "tool_timeouts.search must be non-negative, got -5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := StaticConfig{ListOutput: "json", ToolTimeout: tt.timeout, ToolTimeouts: tt.timeouts}
+			err := cfg.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Expected the configuration to be valid, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"text/template"
+)
+
+// PromptTemplate defines an MCP prompt whose message is rendered from a Go
+// text/template. Arguments are available to the template by name, e.g.
+// {{.question}}; arguments that were not supplied render as "".
+type PromptTemplate struct {
+	Name        string           `mapstructure:"name"`
+	Description string           `mapstructure:"description"`
+	Arguments   []PromptArgument `mapstructure:"arguments"`
+	Template    string           `mapstructure:"template"`
+}
+
+// PromptArgument describes an argument of a prompt template
+type PromptArgument struct {
+	Name        string `mapstructure:"name"`
+	Description string `mapstructure:"description"`
+	Required    bool   `mapstructure:"required"`
+}
+
+// Parse parses the prompt's template
+func (p PromptTemplate) Parse() (*template.Template, error) {
+	return template.New(p.Name).Option("missingkey=zero").Parse(p.Template)
+}
+
+// validatePrompts checks that prompt templates are named uniquely and parse
+func validatePrompts(prompts []PromptTemplate) error {
+	names := make(map[string]bool)
+	for i, p := range prompts {
+		if p.Name == "" {
+			return fmt.Errorf("prompts[%d]: name is required", i)
+		}
+		if names[p.Name] {
+			return fmt.Errorf("prompts[%d]: duplicate prompt name %q", i, p.Name)
+		}
+		names[p.Name] = true
+
+		if p.Template == "" {
+			return fmt.Errorf("prompt %s: template is required", p.Name)
+		}
+		if _, err := p.Parse(); err != nil {
+			return fmt.Errorf("prompt %s: %w", p.Name, err)
+		}
+
+		args := make(map[string]bool)
+		for _, arg := range p.Arguments {
+			if arg.Name == "" {
+				return fmt.Errorf("prompt %s: argument name is required", p.Name)
+			}
+			if args[arg.Name] {
+				return fmt.Errorf("prompt %s: duplicate argument %q", p.Name, arg.Name)
+			}
+			args[arg.Name] = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePrompts(t *testing.T) {
+	valid := PromptTemplate{
+		Name:      "triage",
+		Arguments: []PromptArgument{{Name: "ticket", Required: true}},
+		Template:  "Triage {{.ticket}}",
+	}
+	tests := []struct {
+		name    string
+		prompts []PromptTemplate
+		want    string
+	}{
+		{name: "none"},
+		{name: "valid", prompts: []PromptTemplate{valid}},
+		{name: "missing name", prompts: []PromptTemplate{{Template: "x"}}, want: "prompts[0]: name is required"},
+		{name: "duplicate name", prompts: []PromptTemplate{valid, valid}, want: `prompts[1]: duplicate prompt name "triage"`},
+		{name: "missing template", prompts: []PromptTemplate{{Name: "empty"}}, want: "prompt empty: template is required"},
+		{name: "invalid template", prompts: []PromptTemplate{{Name: "broken", Template: "{{.ticket"}}, want: "prompt broken:"},
+		{
+			name:    "missing argument name",
+			prompts: []PromptTemplate{{Name: "p", Template: "x", Arguments: []PromptArgument{{Description: "unnamed"}}}},
+			want:    "prompt p: argument name is required",
+		},
+		{
+			name:    "duplicate argument",
+			prompts: []PromptTemplate{{Name: "p", Template: "x", Arguments: []PromptArgument{{Name: "a"}, {Name: "a"}}}},
+			want:    `prompt p: duplicate argument "a"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePrompts(tt.prompts)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Expected the prompts to be valid, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestPromptTemplate_MissingArgumentsRenderEmpty(t *testing.T) {
+	tmpl, err := PromptTemplate{Name: "p", Template: "[{{.missing}}]"}.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "[]" {
+		t.Errorf("Expected a missing argument to render empty, got %q", b.String())
+	}
+}
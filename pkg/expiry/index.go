@@ -0,0 +1,220 @@
+package expiry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/core/logging"
+)
+
+// Record is an entry scheduled for deletion
+type Record struct {
+	Collection string    `json:"collection"`
+	Entry      string    `json:"entry"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Index is a file-backed set of expiry records. Every change is written to
+// disk immediately so scheduled deletions survive restarts.
+//
+// The file may be shared between processes, such as the server and the CLI.
+// Changes are made under an advisory lock on a sibling ".lock" file after
+// reloading the file, and reads reload it, so that each process sees and
+// keeps the changes of the others.
+// A nil *Index is valid and behaves as an empty, read-only index.
+type Index struct {
+	mu      sync.Mutex
+	path    string
+	records map[string]Record
+}
+
+// key returns the map key for an entry
+func key(collection, entry string) string {
+	return collection + "/" + entry
+}
+
+// Load opens the index stored at path, creating an empty one if the file does not exist
+func Load(path string) (*Index, error) {
+	idx := &Index{path: path}
+	if err := idx.reload(); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// reload replaces the records with the contents of the file. Callers must
+// hold idx.mu.
+func (idx *Index) reload() error {
+	data, err := os.ReadFile(idx.path)
+	if errors.Is(err, os.ErrNotExist) {
+		idx.records = make(map[string]Record)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read expiry index: %w", err)
+	}
+
+	var records []Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return fmt.Errorf("failed to parse expiry index %s: %w", idx.path, err)
+	}
+	idx.records = make(map[string]Record, len(records))
+	for _, r := range records {
+		idx.records[key(r.Collection, r.Entry)] = r
+	}
+	return nil
+}
+
+// refresh reloads the index before a read. The file is replaced atomically,
+// so no lock is needed; if it cannot be read the last known records are
+// used. Callers must hold idx.mu.
+func (idx *Index) refresh() {
+	if err := idx.reload(); err != nil {
+		logging.Warn("Using the last known expiry records: %v", err)
+	}
+}
+
+// update reloads the index under the file lock and applies change, saving
+// the records if change reports that it modified them
+func (idx *Index) update(change func() bool) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create expiry index directory: %w", err)
+	}
+	unlock, err := lockFile(idx.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("failed to lock expiry index: %w", err)
+	}
+	defer unlock()
+
+	if err := idx.reload(); err != nil {
+		return err
+	}
+	if !change() {
+		return nil
+	}
+	return idx.save()
+}
+
+// Set schedules an entry for deletion at expiresAt, replacing any previous schedule
+func (idx *Index) Set(collection, entry string, expiresAt time.Time) error {
+	if idx == nil {
+		return fmt.Errorf("entry expiry is not enabled")
+	}
+	return idx.update(func() bool {
+		idx.records[key(collection, entry)] = Record{
+			Collection: collection,
+			Entry:      entry,
+			ExpiresAt:  expiresAt.UTC(),
+		}
+		return true
+	})
+}
+
+// Get returns the expiry record of an entry, if any
+func (idx *Index) Get(collection, entry string) (Record, bool) {
+	if idx == nil {
+		return Record{}, false
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.refresh()
+	r, ok := idx.records[key(collection, entry)]
+	return r, ok
+}
+
+// Remove drops the expiry record of an entry
+func (idx *Index) Remove(collection, entry string) error {
+	if idx == nil {
+		return nil
+	}
+	return idx.update(func() bool {
+		k := key(collection, entry)
+		if _, ok := idx.records[k]; !ok {
+			return false
+		}
+		delete(idx.records, k)
+		return true
+	})
+}
+
+// RemoveCollection drops all expiry records of a collection
+func (idx *Index) RemoveCollection(collection string) error {
+	if idx == nil {
+		return nil
+	}
+	return idx.update(func() bool {
+		changed := false
+		for k, r := range idx.records {
+			if r.Collection == collection {
+				delete(idx.records, k)
+				changed = true
+			}
+		}
+		return changed
+	})
+}
+
+// Expired returns the records whose expiry time is at or before now, oldest first
+func (idx *Index) Expired(now time.Time) []Record {
+	if idx == nil {
+		return nil
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.refresh()
+	var expired []Record
+	for _, r := range idx.records {
+		if !r.ExpiresAt.After(now) {
+			expired = append(expired, r)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool {
+		return expired[i].ExpiresAt.Before(expired[j].ExpiresAt)
+	})
+	return expired
+}
+
+// Len returns the number of scheduled entries
+func (idx *Index) Len() int {
+	if idx == nil {
+		return 0
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.refresh()
+	return len(idx.records)
+}
+
+// save writes the index atomically. Callers must hold idx.mu and the file lock.
+func (idx *Index) save() error {
+	records := make([]Record, 0, len(idx.records))
+	for _, r := range idx.records {
+		records = append(records, r)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return key(records[i].Collection, records[i].Entry) < key(records[j].Collection, records[j].Entry)
+	})
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal expiry index: %w", err)
+	}
+
+	tmp := idx.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write expiry index: %w", err)
+	}
+	if err := os.Rename(tmp, idx.path); err != nil {
+		return fmt.Errorf("failed to replace expiry index: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package expiry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIndex_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "expiry.json")
+	idx, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if idx.Len() != 0 {
+		t.Fatalf("Expected an empty index, got %d records", idx.Len())
+	}
+
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+	if err := idx.Set("docs", "a.md", at); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := idx.Set("notes", "b.md", at.Add(time.Hour)); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if reloaded.Len() != 2 {
+		t.Fatalf("Expected 2 records after reload, got %d", reloaded.Len())
+	}
+	r, ok := reloaded.Get("docs", "a.md")
+	if !ok || !r.ExpiresAt.Equal(at) || r.ExpiresAt.Location() != time.UTC {
+		t.Errorf("Expected docs/a.md to expire at %v in UTC, got %v (found %v)", at, r.ExpiresAt, ok)
+	}
+}
+
+func TestIndex_SetReplacesSchedule(t *testing.T) {
+	idx, err := Load(filepath.Join(t.TempDir(), "expiry.json"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	now := time.Now()
+	if err := idx.Set("docs", "a.md", now.Add(-time.Hour)); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := idx.Set("docs", "a.md", now.Add(time.Hour)); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if idx.Len() != 1 || len(idx.Expired(now)) != 0 {
+		t.Errorf("Expected the later schedule to replace the earlier one")
+	}
+}
+
+func TestIndex_Expired(t *testing.T) {
+	idx, err := Load(filepath.Join(t.TempDir(), "expiry.json"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	now := time.Now()
+	schedule := map[string]time.Time{
+		"due.md":    now,
+		"older.md":  now.Add(-2 * time.Hour),
+		"old.md":    now.Add(-time.Hour),
+		"future.md": now.Add(time.Minute),
+	}
+	for entry, at := range schedule {
+		if err := idx.Set("docs", entry, at); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+
+	expired := idx.Expired(now)
+	var entries []string
+	for _, r := range expired {
+		entries = append(entries, r.Entry)
+	}
+	want := []string{"older.md", "old.md", "due.md"}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Fatalf("Expected %v oldest first, got %v", want, entries)
+		}
+	}
+}
+
+func TestIndex_Remove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "expiry.json")
+	idx, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	at := time.Now().Add(time.Hour)
+	for _, r := range []Record{{"docs", "a.md", at}, {"docs", "b.md", at}, {"notes", "c.md", at}} {
+		if err := idx.Set(r.Collection, r.Entry, r.ExpiresAt); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+
+	if err := idx.Remove("docs", "a.md"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := idx.Remove("docs", "missing.md"); err != nil {
+		t.Fatalf("Remove of a missing record failed: %v", err)
+	}
+	if err := idx.RemoveCollection("notes"); err != nil {
+		t.Fatalf("RemoveCollection failed: %v", err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, ok := reloaded.Get("docs", "b.md"); !ok || reloaded.Len() != 1 {
+		t.Errorf("Expected only docs/b.md to remain, got %d records", reloaded.Len())
+	}
+}
+
+func TestIndex_Nil(t *testing.T) {
+	var idx *Index
+	if err := idx.Set("docs", "a.md", time.Now()); err == nil {
+		t.Error("Expected Set on a nil index to fail")
+	}
+	if err := idx.Remove("docs", "a.md"); err != nil {
+		t.Errorf("Expected Remove on a nil index to succeed, got %v", err)
+	}
+	if idx.Len() != 0 || idx.Expired(time.Now()) != nil {
+		t.Error("Expected a nil index to be empty")
+	}
+}
+
+func TestLoad_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "expiry.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Expected a corrupt index to fail to load")
+	}
+}
+
+func TestIndex_SharedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "expiry.json")
+	server, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	cli, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	at := time.Now().Add(time.Hour)
+	if err := server.Set("docs", "a.md", at); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := cli.Set("docs", "b.md", at); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if _, ok := server.Get("docs", "b.md"); !ok || server.Len() != 2 {
+		t.Errorf("Expected the server to see the record set by the CLI, got %d records", server.Len())
+	}
+
+	if err := server.Remove("docs", "b.md"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := cli.Set("docs", "c.md", at); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	_, hasA := reloaded.Get("docs", "a.md")
+	_, hasB := reloaded.Get("docs", "b.md")
+	_, hasC := reloaded.Get("docs", "c.md")
+	if !hasA || hasB || !hasC {
+		t.Errorf("Expected a.md and c.md to remain, got a=%v b=%v c=%v", hasA, hasB, hasC)
+	}
+}
+
+func TestIndex_ConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "expiry.json")
+	const writers, entries = 4, 10
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*entries)
+	for w := 0; w < writers; w++ {
+		idx, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < entries; i++ {
+				errs <- idx.Set("docs", fmt.Sprintf("%d-%d.md", w, i), time.Now())
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if reloaded.Len() != writers*entries {
+		t.Errorf("Expected %d records, got %d", writers*entries, reloaded.Len())
+	}
+}
+
+func TestIndex_KeepsRecordsWhenFileUnreadable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "expiry.json")
+	idx, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := idx.Set("docs", "a.md", time.Now()); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := idx.Get("docs", "a.md"); !ok {
+		t.Error("Expected the last known records to be used")
+	}
+	if err := idx.Set("docs", "b.md", time.Now()); err == nil {
+		t.Error("Expected a change to fail rather than overwrite an unreadable index")
+	}
+}
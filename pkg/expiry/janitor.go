@@ -0,0 +1,98 @@
+package expiry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/core/logging"
+)
+
+// DeleteFunc deletes an entry from LocalRecall
+type DeleteFunc func(ctx context.Context, collection, entry string) error
+
+// ExistsFunc reports whether an entry still exists in LocalRecall
+type ExistsFunc func(ctx context.Context, collection, entry string) (bool, error)
+
+// Janitor periodically deletes expired entries recorded in an Index
+type Janitor struct {
+	index    *Index
+	interval time.Duration
+	delete   DeleteFunc
+	exists   ExistsFunc
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewJanitor creates a janitor that sweeps the index every interval
+func NewJanitor(index *Index, interval time.Duration, deleteFn DeleteFunc, existsFn ExistsFunc) *Janitor {
+	return &Janitor{
+		index:    index,
+		interval: interval,
+		delete:   deleteFn,
+		exists:   existsFn,
+	}
+}
+
+// Start runs the janitor in a background goroutine. An initial sweep runs
+// immediately so entries that expired while the server was down are removed.
+func (j *Janitor) Start(ctx context.Context) {
+	ctx, j.cancel = context.WithCancel(ctx)
+	j.done = make(chan struct{})
+
+	go func() {
+		defer close(j.done)
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		j.Sweep(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.Sweep(ctx)
+			}
+		}
+	}()
+	logging.Info("Expiry janitor started (interval: %s, scheduled entries: %d)", j.interval, j.index.Len())
+}
+
+// Stop stops the janitor and waits for an in-progress sweep to finish
+func (j *Janitor) Stop() {
+	j.once.Do(func() {
+		if j.cancel == nil {
+			return
+		}
+		j.cancel()
+		<-j.done
+		logging.Info("Expiry janitor stopped")
+	})
+}
+
+// Sweep deletes all entries that have expired. Entries that fail to delete
+// are retried on the next sweep unless they no longer exist.
+func (j *Janitor) Sweep(ctx context.Context) {
+	for _, r := range j.index.Expired(time.Now()) {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err := j.delete(ctx, r.Collection, r.Entry); err != nil {
+			exists, existsErr := j.exists(ctx, r.Collection, r.Entry)
+			if existsErr != nil || exists {
+				logging.Warn("Failed to delete expired entry %s/%s: %v", r.Collection, r.Entry, err)
+				continue
+			}
+			logging.Info("Expired entry %s/%s no longer exists", r.Collection, r.Entry)
+		} else {
+			logging.Info("Deleted expired entry %s/%s (expired at %s)", r.Collection, r.Entry, r.ExpiresAt.Format(time.RFC3339))
+		}
+
+		if err := j.index.Remove(r.Collection, r.Entry); err != nil {
+			logging.Error("Failed to update expiry index: %v", err)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package mcp
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/client"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+)
+
+// completionCacheTTL is how long listed names are reused while a user types
+const completionCacheTTL = 10 * time.Second
+
+// maxCompletionValues is the most values a completion may return
+const maxCompletionValues = 100
+
+// Argument names completed with collection, entry and source names
+var (
+	collectionArguments = []string{
+		"collection", "collection_name", "name", "source_collection", "target_collection",
+		"other_collection", "collection_a", "collection_b",
+	}
+	entryArguments  = []string{"entry", "other_entry", "target_entry"}
+	sourceArguments = []string{"url"}
+)
+
+// completionProvider completes prompt and resource template arguments naming
+// collections, entries and registered sources
+type completionProvider struct {
+	client   *client.Client
+	isolated string
+	allowed  func(collection string) bool
+	names    *toolset.Cache[[]string]
+}
+
+// newCompletionProvider creates a completion provider listing names with c
+func newCompletionProvider(c *client.Client, isolated string, allowed func(string) bool) *completionProvider {
+	return &completionProvider{
+		client:   c,
+		isolated: isolated,
+		allowed:  allowed,
+		names:    toolset.NewCache[[]string](completionCacheTTL),
+	}
+}
+
+// CompletePromptArgument implements server.PromptCompletionProvider
+func (p *completionProvider) CompletePromptArgument(ctx context.Context, _ string, argument mcp.CompleteArgument, completeCtx mcp.CompleteContext) (*mcp.Completion, error) {
+	return p.complete(ctx, argument, completeCtx)
+}
+
+// CompleteResourceArgument implements server.ResourceCompletionProvider
+func (p *completionProvider) CompleteResourceArgument(ctx context.Context, _ string, argument mcp.CompleteArgument, completeCtx mcp.CompleteContext) (*mcp.Completion, error) {
+	return p.complete(ctx, argument, completeCtx)
+}
+
+// complete suggests names for an argument based on its name. Listing errors
+// produce no suggestions rather than failing the request.
+func (p *completionProvider) complete(ctx context.Context, argument mcp.CompleteArgument, completeCtx mcp.CompleteContext) (*mcp.Completion, error) {
+	var candidates []string
+	switch {
+	case slices.Contains(collectionArguments, argument.Name):
+		candidates = p.collections(ctx)
+	case slices.Contains(entryArguments, argument.Name):
+		if collection := p.contextCollection(completeCtx); collection != "" {
+			candidates = p.entries(ctx, collection)
+		}
+	case slices.Contains(sourceArguments, argument.Name):
+		if collection := p.contextCollection(completeCtx); collection != "" {
+			candidates = p.sources(ctx, collection)
+		}
+	}
+	return filterCompletions(candidates, argument.Value), nil
+}
+
+// contextCollection returns the collection already chosen for the request:
+// the isolated collection, or an accessible collection argument
+func (p *completionProvider) contextCollection(completeCtx mcp.CompleteContext) string {
+	if p.isolated != "" {
+		return p.isolated
+	}
+	for _, name := range []string{"collection_name", "collection", "source_collection"} {
+		if collection := completeCtx.Arguments[name]; collection != "" && p.allowed(collection) {
+			return collection
+		}
+	}
+	return ""
+}
+
+// collections lists the accessible collections
+func (p *completionProvider) collections(ctx context.Context) []string {
+	if p.isolated != "" {
+		return []string{p.isolated}
+	}
+	return p.cached("collections", func() ([]string, error) {
+		result, err := p.client.ListCollections(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, name := range result.Collections {
+			if p.allowed(name) {
+				names = append(names, name)
+			}
+		}
+		return names, nil
+	})
+}
+
+// entries lists the entries of a collection
+func (p *completionProvider) entries(ctx context.Context, collection string) []string {
+	return p.cached("entries:"+collection, func() ([]string, error) {
+		result, err := p.client.ListFiles(ctx, collection)
+		if err != nil {
+			return nil, err
+		}
+		return result.Entries, nil
+	})
+}
+
+// sources lists the URLs of the sources registered for a collection
+func (p *completionProvider) sources(ctx context.Context, collection string) []string {
+	return p.cached("sources:"+collection, func() ([]string, error) {
+		result, err := p.client.ListSources(ctx, collection)
+		if err != nil {
+			return nil, err
+		}
+		var urls []string
+		for _, src := range result.Sources {
+			if url, _ := src["url"].(string); url != "" {
+				urls = append(urls, url)
+			}
+		}
+		return urls, nil
+	})
+}
+
+// cached returns the names stored under key, listing them on a miss
+func (p *completionProvider) cached(key string, list func() ([]string, error)) []string {
+	if names, ok := p.names.Get(key); ok {
+		return names
+	}
+	names, err := list()
+	if err != nil {
+		return nil
+	}
+	slices.Sort(names)
+	p.names.Set(key, names)
+	return names
+}
+
+// filterCompletions returns the candidates starting with prefix, ignoring case
+func filterCompletions(candidates []string, prefix string) *mcp.Completion {
+	prefix = strings.ToLower(prefix)
+	values := []string{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(strings.ToLower(candidate), prefix) {
+			values = append(values, candidate)
+		}
+	}
+
+	completion := &mcp.Completion{Values: values, Total: len(values)}
+	if len(values) > maxCompletionValues {
+		completion.Values = values[:maxCompletionValues]
+		completion.HasMore = true
+	}
+	return completion
+}
@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/futuretea/localrecall-mcp-server/internal/lrtest"
+)
+
+func TestFilterCompletions(t *testing.T) {
+	completion := filterCompletions([]string{"Docs", "drafts", "kb"}, "D")
+	if !slices.Equal(completion.Values, []string{"Docs", "drafts"}) || completion.Total != 2 || completion.HasMore {
+		t.Errorf("Expected the case-insensitive prefix matches, got %+v", completion)
+	}
+	if completion := filterCompletions(nil, "x"); completion.Values == nil || len(completion.Values) != 0 {
+		t.Errorf("Expected an empty, non-nil list, got %+v", completion)
+	}
+
+	var many []string
+	for i := range maxCompletionValues + 5 {
+		many = append(many, fmt.Sprintf("entry-%03d", i))
+	}
+	completion = filterCompletions(many, "entry-")
+	if len(completion.Values) != maxCompletionValues || completion.Total != maxCompletionValues+5 || !completion.HasMore {
+		t.Errorf("Expected the values to be capped at %d, got %d of %d", maxCompletionValues, len(completion.Values), completion.Total)
+	}
+}
+
+func TestCompletionProvider(t *testing.T) {
+	backend := lrtest.NewServer(t)
+	backend.AddEntry("docs", "guide.md", "g")
+	backend.AddEntry("docs", "Getting-started.md", "g")
+	backend.AddEntry("drafts", "notes.md", "n")
+	backend.AddEntry("private", "secret.md", "s")
+	backend.AddSource("docs", "https://example.com/feed", 60)
+
+	allowed := func(collection string) bool { return collection != "private" }
+	complete := func(p *completionProvider, argument, value string, args map[string]string) []string {
+		t.Helper()
+		completion, err := p.CompletePromptArgument(context.Background(), "prompt",
+			mcp.CompleteArgument{Name: argument, Value: value}, mcp.CompleteContext{Arguments: args})
+		if err != nil {
+			t.Fatalf("Completion failed: %v", err)
+		}
+		return completion.Values
+	}
+
+	p := newCompletionProvider(backend.Client(), "", allowed)
+	tests := []struct {
+		name     string
+		argument string
+		value    string
+		args     map[string]string
+		want     []string
+	}{
+		{name: "collections", argument: "collection", want: []string{"docs", "drafts"}},
+		{name: "collection prefix", argument: "target_collection", value: "dr", want: []string{"drafts"}},
+		{name: "disallowed collection", argument: "collection_name", value: "priv", want: []string{}},
+		{name: "entries need a collection", argument: "entry", want: []string{}},
+		{name: "entries", argument: "entry", value: "g", args: map[string]string{"collection": "docs"}, want: []string{"Getting-started.md", "guide.md"}},
+		{name: "entries of the supplied collection", argument: "other_entry", args: map[string]string{"collection_name": "drafts"}, want: []string{"notes.md"}},
+		{name: "entries of a disallowed collection", argument: "entry", args: map[string]string{"collection": "private"}, want: []string{}},
+		{name: "sources", argument: "url", args: map[string]string{"collection": "docs"}, want: []string{"https://example.com/feed"}},
+		{name: "other arguments", argument: "question", value: "d", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := complete(p, tt.argument, tt.value, tt.args); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("isolated", func(t *testing.T) {
+		isolated := newCompletionProvider(backend.Client(), "drafts", allowed)
+		if got := complete(isolated, "collection", "", nil); !slices.Equal(got, []string{"drafts"}) {
+			t.Errorf("Expected only the isolated collection, got %v", got)
+		}
+		if got := complete(isolated, "entry", "", map[string]string{"collection": "docs"}); !slices.Equal(got, []string{"notes.md"}) {
+			t.Errorf("Expected the entries of the isolated collection, got %v", got)
+		}
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		backend.AddEntry("docs", "new.md", "n")
+		if got := complete(p, "entry", "new", map[string]string{"collection": "docs"}); len(got) != 0 {
+			t.Errorf("Expected the cached entry list to be reused, got %v", got)
+		}
+	})
+}
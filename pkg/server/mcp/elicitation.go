@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+)
+
+// confirmSchema is the form asking the user to confirm a call
+var confirmSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"confirm": map[string]interface{}{
+			"type":        "boolean",
+			"title":       "Confirm",
+			"description": "Proceed with the operation",
+			"default":     false,
+		},
+	},
+	"required": []string{"confirm"},
+}
+
+// elicitationSupported reports whether the client that made the current
+// request declared the elicitation capability
+func elicitationSupported(ctx context.Context) bool {
+	session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo)
+	return ok && session.GetClientCapabilities().Elicitation != nil
+}
+
+// elicitCollection asks the user which collection to use when a tool call in
+// multi-collection mode names none. params are left unchanged when the client
+// cannot be asked, so the call fails as before.
+func (s *Server) elicitCollection(ctx context.Context, tool mcp.Tool, params map[string]interface{}) error {
+	if s.configuration.LocalRecallCollection != "" {
+		return nil
+	}
+	if _, ok := tool.InputSchema.Properties["collection_name"]; !ok {
+		return nil
+	}
+	if name, _ := params["collection_name"].(string); name != "" || !elicitationSupported(ctx) {
+		return nil
+	}
+
+	list, err := s.localRecallClient.ListCollections(ctx)
+	if err != nil {
+		return fmt.Errorf("collection_name is required: failed to list collections: %w", err)
+	}
+	var names []string
+	for _, name := range list.Collections {
+		if s.collectionAllowed(name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("collection_name is required, and no collection is available")
+	}
+	slices.Sort(names)
+
+	result, err := s.server.RequestElicitation(ctx, mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{
+			Message: fmt.Sprintf("%s: which collection should be used?", tool.Annotations.Title),
+			RequestedSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"collection": map[string]interface{}{
+						"type":        "string",
+						"title":       "Collection",
+						"description": "The LocalRecall collection to use",
+						"enum":        names,
+					},
+				},
+				"required": []string{"collection"},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("collection_name is required: %w", err)
+	}
+	collection, _ := elicitedValue(result, "collection").(string)
+	if collection == "" {
+		return fmt.Errorf("collection_name is required")
+	}
+	params["collection_name"] = collection
+	return nil
+}
+
+// confirmTool asks the user to confirm a call of a tool annotated as
+// destructive that would delete or overwrite content. Calls the client cannot
+// confirm proceed unless require_confirmation is set.
+func (s *Server) confirmTool(ctx context.Context, tool toolset.ServerTool, client interface{}, params map[string]interface{}) error {
+	if hint := tool.Tool.Annotations.DestructiveHint; hint == nil || !*hint {
+		return nil
+	}
+	supported := elicitationSupported(ctx)
+	if !supported && !s.configuration.RequireConfirmation {
+		return nil
+	}
+
+	toolName := tool.Tool.Name
+	message := fmt.Sprintf("Run %s? It can delete or overwrite content.", toolName)
+	if tool.Confirm != nil {
+		message = tool.Confirm(ctx, client, params)
+	}
+	if message == "" {
+		return nil
+	}
+	if !supported {
+		return fmt.Errorf("%s requires confirmation, but the client does not support elicitation", toolName)
+	}
+
+	result, err := s.server.RequestElicitation(ctx, mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{Message: message, RequestedSchema: confirmSchema},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to confirm %s: %w", toolName, err)
+	}
+	if confirmed, _ := elicitedValue(result, "confirm").(bool); !confirmed {
+		return fmt.Errorf("%s was not confirmed by the user; nothing was changed", toolName)
+	}
+	return nil
+}
+
+// elicitedValue returns a field of an accepted elicitation response, or nil
+// if the user declined or cancelled
+func elicitedValue(result *mcp.ElicitationResult, field string) interface{} {
+	if result.Action != mcp.ElicitationResponseActionAccept {
+		return nil
+	}
+	content, _ := result.Content.(map[string]interface{})
+	return content[field]
+}
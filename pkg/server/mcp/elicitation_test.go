@@ -0,0 +1,180 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/core/config"
+)
+
+// elicitor answers elicitation requests with a fixed response and records them
+type elicitor struct {
+	result   mcp.ElicitationResult
+	requests []mcp.ElicitationRequest
+}
+
+func (e *elicitor) Elicit(_ context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	e.requests = append(e.requests, request)
+	result := e.result
+	return &result, nil
+}
+
+// connectWithElicitation returns a client that declares elicitation and
+// answers with e
+func connectWithElicitation(t *testing.T, s *Server, e *elicitor) *mcpclient.Client {
+	t.Helper()
+	return initialize(t, mcpclient.NewClient(
+		transport.NewInProcessTransportWithOptions(s.server, transport.WithElicitationHandler(e)),
+		mcpclient.WithElicitationHandler(e),
+	))
+}
+
+// callToolText calls a tool and returns its text and whether it failed
+func callToolText(t *testing.T, client *mcpclient.Client, name string, args map[string]any) (string, bool) {
+	t.Helper()
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = args
+	result, err := client.CallTool(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Failed to call %s: %v", name, err)
+	}
+	return mcp.GetTextFromContent(result.Content[0]), result.IsError
+}
+
+func accept(content map[string]any) mcp.ElicitationResult {
+	return mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionAccept, Content: content}}
+}
+
+func TestConfirmTool(t *testing.T) {
+	tests := []struct {
+		name      string
+		result    mcp.ElicitationResult
+		deleted   bool
+		wantError string
+	}{
+		{name: "confirmed", result: accept(map[string]any{"confirm": true}), deleted: true},
+		{name: "unconfirmed", result: accept(map[string]any{"confirm": false}), wantError: "delete_entry was not confirmed by the user"},
+		{
+			name:      "declined",
+			result:    mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionDecline}},
+			wantError: "delete_entry was not confirmed by the user",
+		},
+		{
+			name:      "cancelled",
+			result:    mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionCancel}},
+			wantError: "delete_entry was not confirmed by the user",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend, s := newTestServer(t, config.StaticConfig{})
+			backend.AddEntry("docs", "a.md", "a")
+			backend.AddEntry("docs", "b.md", "b")
+			e := &elicitor{result: tt.result}
+
+			text, failed := callToolText(t, connectWithElicitation(t, s, e), "delete_entry", map[string]any{"collection_name": "docs", "entry": "a.md"})
+			if len(e.requests) != 1 {
+				t.Fatalf("Expected one confirmation request, got %d", len(e.requests))
+			}
+			want := `Delete entry "a.md" from collection "docs"? It will be permanently deleted; 1 of the collection's 2 entries will remain.`
+			if message := e.requests[0].Params.Message; message != want {
+				t.Errorf("Expected the message %q, got %q", want, message)
+			}
+			if tt.wantError != "" && (!failed || !strings.Contains(text, tt.wantError)) {
+				t.Errorf("Expected an error containing %q, got %q", tt.wantError, text)
+			}
+			if _, exists := backend.Entry("docs", "a.md"); exists == tt.deleted {
+				t.Errorf("Expected deleted=%v, got %q", tt.deleted, text)
+			}
+		})
+	}
+}
+
+func TestConfirmTool_NothingToConfirm(t *testing.T) {
+	backend, s := newTestServer(t, config.StaticConfig{})
+	backend.AddCollection("docs")
+	e := &elicitor{result: accept(map[string]any{"confirm": false})}
+	client := connectWithElicitation(t, s, e)
+
+	// Adding a new entry overwrites nothing
+	if text, failed := callToolText(t, client, "add_document", map[string]any{"collection_name": "docs", "filename": "new.md", "file_content": "x"}); failed {
+		t.Fatalf("Expected the call to succeed, got %q", text)
+	}
+	// A dry run changes nothing
+	if text, failed := callToolText(t, client, "rename_collection", map[string]any{"source_collection": "docs", "target_collection": "kb", "dry_run": true}); failed {
+		t.Fatalf("Expected the dry run to succeed, got %q", text)
+	}
+	if len(e.requests) != 0 {
+		t.Errorf("Expected no confirmation requests, got %v", e.requests)
+	}
+}
+
+func TestConfirmTool_Unsupported(t *testing.T) {
+	for _, require := range []bool{false, true} {
+		backend, s := newTestServer(t, config.StaticConfig{RequireConfirmation: require})
+		backend.AddEntry("docs", "a.md", "a")
+
+		text, failed := callToolText(t, connect(t, s), "delete_entry", map[string]any{"collection_name": "docs", "entry": "a.md"})
+		_, exists := backend.Entry("docs", "a.md")
+		if !require && (failed || exists) {
+			t.Errorf("Expected the call to proceed without require_confirmation, got %q", text)
+		}
+		if require && (!failed || !exists || !strings.Contains(text, "delete_entry requires confirmation, but the client does not support elicitation")) {
+			t.Errorf("Expected the call to be refused with require_confirmation, got %q", text)
+		}
+	}
+}
+
+func TestElicitCollection(t *testing.T) {
+	t.Run("accepted", func(t *testing.T) {
+		backend, s := newTestServer(t, config.StaticConfig{AllowedCollections: []string{"docs", "kb"}})
+		backend.AddEntry("kb", "a.md", "a")
+		backend.AddCollection("docs")
+		backend.AddCollection("private")
+		e := &elicitor{result: accept(map[string]any{"collection": "kb"})}
+
+		text, failed := callToolText(t, connectWithElicitation(t, s, e), "list_files", map[string]any{})
+		if failed || !strings.Contains(text, "a.md") {
+			t.Errorf("Expected the entries of the chosen collection, got %q", text)
+		}
+		if len(e.requests) != 1 {
+			t.Fatalf("Expected one elicitation request, got %d", len(e.requests))
+		}
+		property, _ := e.requests[0].Params.RequestedSchema.(map[string]interface{})["properties"].(map[string]interface{})["collection"].(map[string]interface{})
+		if choices, _ := property["enum"].([]string); strings.Join(choices, ",") != "docs,kb" {
+			t.Errorf("Expected the allowed collections as choices, got %v", property["enum"])
+		}
+	})
+
+	t.Run("declined", func(t *testing.T) {
+		backend, s := newTestServer(t, config.StaticConfig{})
+		backend.AddCollection("docs")
+		e := &elicitor{result: mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionDecline}}}
+
+		text, failed := callToolText(t, connectWithElicitation(t, s, e), "list_files", map[string]any{})
+		if !failed || !strings.Contains(text, "collection_name is required") {
+			t.Errorf("Expected the call to fail without a collection, got %q", text)
+		}
+	})
+
+	t.Run("named or isolated", func(t *testing.T) {
+		backend, s := newTestServer(t, config.StaticConfig{})
+		backend.AddEntry("docs", "a.md", "a")
+		e := &elicitor{}
+		if text, failed := callToolText(t, connectWithElicitation(t, s, e), "list_files", map[string]any{"collection_name": "docs"}); failed {
+			t.Errorf("Expected the call to succeed, got %q", text)
+		}
+
+		_, isolated := newTestServer(t, config.StaticConfig{LocalRecallCollection: "docs"})
+		callToolText(t, connectWithElicitation(t, isolated, e), "list_files", map[string]any{})
+		if len(e.requests) != 0 {
+			t.Errorf("Expected no elicitation when the collection is known, got %v", e.requests)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/futuretea/localrecall-mcp-server/internal/lrtest"
+	"github.com/futuretea/localrecall-mcp-server/pkg/core/config"
+)
+
+// newTestServer starts a fake LocalRecall server and an MCP server using it
+func newTestServer(t *testing.T, cfg config.StaticConfig) (*lrtest.Server, *Server) {
+	t.Helper()
+	backend := lrtest.NewServer(t)
+	cfg.LocalRecallURL = backend.URL
+	if cfg.ListOutput == "" {
+		cfg.ListOutput = "json"
+	}
+	s, err := NewServer(Configuration{StaticConfig: &cfg})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	return backend, s
+}
+
+// connect returns an initialized in-process client of the server
+func connect(t *testing.T, s *Server, options ...transport.InProcessOption) *mcpclient.Client {
+	t.Helper()
+	return initialize(t, mcpclient.NewClient(transport.NewInProcessTransportWithOptions(s.server, options...)))
+}
+
+// initialize starts a client and initializes its session
+func initialize(t *testing.T, client *mcpclient.Client) *mcpclient.Client {
+	t.Helper()
+	t.Cleanup(func() { _ = client.Close() })
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	request := mcp.InitializeRequest{}
+	request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	request.Params.ClientInfo = mcp.Implementation{Name: "test", Version: "1.0.0"}
+	if _, err := client.Initialize(context.Background(), request); err != nil {
+		t.Fatalf("Failed to initialize client: %v", err)
+	}
+	return client
+}
+
+// readResourceText reads a resource that must have a single text content
+func readResourceText(t *testing.T, client *mcpclient.Client, uri string) string {
+	t.Helper()
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = uri
+	result, err := client.ReadResource(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", uri, err)
+	}
+	if len(result.Contents) != 1 {
+		t.Fatalf("Expected one content for %s, got %d", uri, len(result.Contents))
+	}
+	text, ok := result.Contents[0].(mcp.TextResourceContents)
+	if !ok {
+		t.Fatalf("Expected text content for %s, got %T", uri, result.Contents[0])
+	}
+	return text.Text
+}
+
+// testSession is a client session collecting the notifications sent to it
+type testSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *testSession) Initialize()       {}
+func (s *testSession) Initialized() bool { return true }
+func (s *testSession) SessionID() string { return s.id }
+func (s *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+// newTestSession registers a session with the server
+func newTestSession(t *testing.T, s *Server, id string) *testSession {
+	t.Helper()
+	session := &testSession{id: id, notifications: make(chan mcp.JSONRPCNotification, 100)}
+	if err := s.server.RegisterSession(context.Background(), session); err != nil {
+		t.Fatalf("Failed to register session: %v", err)
+	}
+	t.Cleanup(func() { s.server.UnregisterSession(context.Background(), id) })
+	return session
+}
+
+// drainNotifications returns the notifications sent to a session so far
+func drainNotifications(session *testSession) []mcp.JSONRPCNotification {
+	var notifications []mcp.JSONRPCNotification
+	for {
+		select {
+		case n := <-session.notifications:
+			notifications = append(notifications, n)
+		default:
+			return notifications
+		}
+	}
+}
+
+// stringParam returns a string notification parameter ("" if unset)
+func stringParam(params map[string]any, name string) string {
+	value, _ := params[name].(string)
+	return value
+}
@@ -2,9 +2,12 @@ package mcp
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"maps"
 	"net/http"
 	"slices"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -13,8 +16,11 @@ import (
 	"github.com/futuretea/localrecall-mcp-server/pkg/core/config"
 	"github.com/futuretea/localrecall-mcp-server/pkg/core/logging"
 	"github.com/futuretea/localrecall-mcp-server/pkg/core/version"
+	"github.com/futuretea/localrecall-mcp-server/pkg/expiry"
 	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
 	localrecallToolset "github.com/futuretea/localrecall-mcp-server/pkg/toolset/localrecall"
+	"github.com/futuretea/localrecall-mcp-server/pkg/versions"
 )
 
 // contextKey is a custom type for context keys to avoid collisions
@@ -33,14 +39,20 @@ type Server struct {
 	server            *server.MCPServer
 	enabledTools      []string
 	localRecallClient *client.Client
+	toolsetClient     *toolset.LocalRecallClient
+	janitor           *expiry.Janitor
+	watcher           *subscriptionWatcher
 }
 
 // NewServer creates a new MCP server with the given configuration
 func NewServer(configuration Configuration) (*Server, error) {
+	hooks := &server.Hooks{}
 	serverOptions := []server.ServerOption{
+		server.WithHooks(hooks),
 		server.WithResourceCapabilities(true, true),
 		server.WithPromptCapabilities(true),
 		server.WithToolCapabilities(true),
+		server.WithElicitation(),
 		server.WithLogging(),
 	}
 
@@ -50,34 +62,102 @@ func NewServer(configuration Configuration) (*Server, error) {
 	)
 	logging.Info("LocalRecall client initialized with URL: %s", configuration.LocalRecallURL)
 
+	var expiryIndex *expiry.Index
+	if configuration.ExpiryIndexPath != "" {
+		var err error
+		expiryIndex, err = expiry.Load(configuration.ExpiryIndexPath)
+		if err != nil {
+			return nil, err
+		}
+		logging.Info("Expiry index loaded from %s", configuration.ExpiryIndexPath)
+	}
+
+	var versionStore *versions.Store
+	if configuration.VersionStorePath != "" {
+		var err error
+		versionStore, err = versions.Open(configuration.VersionStorePath, configuration.MaxVersions)
+		if err != nil {
+			return nil, err
+		}
+		logging.Info("Entry versioning enabled (store: %s)", configuration.VersionStorePath)
+	}
+
 	s := &Server{
 		configuration:     &configuration,
-		server:            server.NewMCPServer(version.BinaryName, version.Version, serverOptions...),
 		localRecallClient: localRecallClient,
+		toolsetClient: &toolset.LocalRecallClient{
+			Client:          localRecallClient,
+			Contents:        toolset.NewCache[*client.EntryContent](time.Duration(configuration.ContentCacheTTL) * time.Second),
+			Computed:        toolset.NewCache[any](time.Duration(configuration.StatsCacheTTL) * time.Second),
+			Expiry:          expiryIndex,
+			Versions:        versionStore,
+			ScanConcurrency: configuration.ScanConcurrency,
+		},
 	}
 
+	// Suggest collection, entry and source names for prompt and resource arguments
+	completions := newCompletionProvider(localRecallClient, configuration.LocalRecallCollection, s.collectionAllowed)
+	serverOptions = append(serverOptions,
+		server.WithCompletions(),
+		server.WithPromptCompletionProvider(completions),
+		server.WithResourceCompletionProvider(completions),
+	)
+	s.server = server.NewMCPServer(version.BinaryName, version.Version, serverOptions...)
+
+	// Let the ask tool have the client's model answer from search results
+	s.server.EnableSampling()
+	s.toolsetClient.Sample = s.sample
+
 	if err := s.registerTools(); err != nil {
 		return nil, err
 	}
+	s.registerResources(hooks)
+	if err := s.registerPrompts(); err != nil {
+		return nil, err
+	}
+	s.watchSubscriptions(hooks)
+
+	if expiryIndex != nil {
+		s.startJanitor(expiryIndex)
+	}
 
 	return s, nil
 }
 
+// startJanitor starts the background deletion of expired entries
+func (s *Server) startJanitor(index *expiry.Index) {
+	deleteEntry := func(ctx context.Context, collection, entry string) error {
+		if _, err := s.localRecallClient.DeleteEntry(ctx, collection, entry); err != nil {
+			return err
+		}
+		s.toolsetClient.InvalidateEntry(collection, entry)
+		s.watcher.Check(ctx, []string{collection})
+		return nil
+	}
+	entryExists := func(ctx context.Context, collection, entry string) (bool, error) {
+		files, err := s.localRecallClient.ListFiles(ctx, collection)
+		if err != nil {
+			return false, err
+		}
+		return slices.Contains(files.Entries, entry), nil
+	}
+
+	interval := time.Duration(s.configuration.JanitorInterval) * time.Second
+	s.janitor = expiry.NewJanitor(index, interval, deleteEntry, entryExists)
+	s.janitor.Start(context.Background())
+}
+
 // registerTools registers all available tools based on configuration
 func (s *Server) registerTools() error {
 	localrecallTs := &localrecallToolset.Toolset{
 		DefaultCollection: s.configuration.LocalRecallCollection,
 	}
 
-	wrappedClient := &toolset.LocalRecallClient{
-		Client: s.localRecallClient,
-	}
-
-	for _, tool := range localrecallTs.GetTools(wrappedClient) {
+	for _, tool := range localrecallTs.GetTools(s.toolsetClient) {
 		if !s.shouldEnableTool(tool.Tool.Name) {
 			continue
 		}
-		s.registerTool(s.configureTool(tool, wrappedClient))
+		s.registerTool(s.configureTool(tool, s.toolsetClient))
 	}
 
 	logging.Info("MCP server initialized with %d tools", len(s.enabledTools))
@@ -97,24 +177,112 @@ func (s *Server) shouldEnableTool(toolName string) bool {
 
 // configureTool creates a configured tool handler that uses server configuration
 func (s *Server) configureTool(tool toolset.ServerTool, wrappedClient *toolset.LocalRecallClient) toolset.ServerTool {
+	readOnly := tool.Tool.Annotations.ReadOnlyHint != nil && *tool.Tool.Annotations.ReadOnlyHint
+	_, acceptsMaxTokens := tool.Tool.InputSchema.Properties["max_tokens"]
+
 	return toolset.ServerTool{
-		Tool: tool.Tool,
-		Handler: func(client interface{}, params map[string]interface{}) (string, error) {
+		Tool:    tool.Tool,
+		Confirm: tool.Confirm,
+		Handler: func(ctx context.Context, client interface{}, params map[string]interface{}) (string, error) {
 			// Inject default output format if not specified
 			if _, hasFormat := params["format"]; !hasFormat && s.configuration.ListOutput != "" {
 				params["format"] = s.configuration.ListOutput
 			}
 
+			// Inject default token budget if not specified, for tools that take one
+			if _, hasMaxTokens := params["max_tokens"]; acceptsMaxTokens && !hasMaxTokens && s.configuration.MaxTokens > 0 {
+				params["max_tokens"] = s.configuration.MaxTokens
+			}
+
 			// Enforce collection isolation: always override collection_name
 			if s.configuration.LocalRecallCollection != "" {
 				params["collection_name"] = s.configuration.LocalRecallCollection
+				// Tools addressing a second collection are confined as well
+				for _, key := range secondaryCollectionParams {
+					if _, ok := params[key]; ok {
+						params[key] = s.configuration.LocalRecallCollection
+					}
+				}
+			}
+
+			// Ask the user for a missing collection before checking access
+			if err := s.elicitCollection(ctx, tool.Tool, params); err != nil {
+				return "", err
+			}
+			if err := s.checkAllowedCollections(tool.Tool.Name, params); err != nil {
+				return "", err
+			}
+
+			// Confirm destructive calls before the timeout starts, so the
+			// user's answer does not count against it
+			if err := s.confirmTool(ctx, tool, wrappedClient, params); err != nil {
+				return "", err
 			}
 
-			return tool.Handler(wrappedClient, params)
+			// Apply the tool's timeout on top of the request context
+			if timeout := s.toolTimeout(tool.Tool.Name); timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			result, err := tool.Handler(ctx, wrappedClient, params)
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return "", fmt.Errorf("%s timed out after %s: %w", tool.Tool.Name, s.toolTimeout(tool.Tool.Name), err)
+			}
+
+			// Notify subscribers of changes made by the call before returning
+			if collections := collectionParams(tool.Tool.Name, params); err == nil && !readOnly && s.watcher.Watches(collections) {
+				s.watcher.Check(ctx, collections)
+			}
+			return result, err
 		},
 	}
 }
 
+// toolTimeout returns the timeout of a tool: its tool_timeouts entry if
+// present, otherwise tool_timeout (0 = no timeout)
+func (s *Server) toolTimeout(toolName string) time.Duration {
+	seconds, ok := s.configuration.ToolTimeouts[toolName]
+	if !ok {
+		seconds = s.configuration.ToolTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// secondaryCollectionParams name a second collection addressed by a tool
+var secondaryCollectionParams = []string{"other_collection", "target_collection"}
+
+// collectionParams returns the collections named in a tool call's parameters
+func collectionParams(toolName string, params map[string]interface{}) []string {
+	keys := append([]string{"collection_name", "source_collection", "collection_a", "collection_b"}, secondaryCollectionParams...)
+	if toolName == "create_collection" || toolName == "reset_collection" {
+		keys = append(keys, "name")
+	}
+	var collections []string
+	for _, key := range keys {
+		if name, ok := params[key].(string); ok && name != "" {
+			collections = append(collections, name)
+		}
+	}
+	return collections
+}
+
+// checkAllowedCollections rejects calls naming a collection outside allowed_collections
+func (s *Server) checkAllowedCollections(toolName string, params map[string]interface{}) error {
+	allowed := s.configuration.AllowedCollections
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	for _, name := range collectionParams(toolName, params) {
+		if !slices.Contains(allowed, name) {
+			return fmt.Errorf("collection %q is not in allowed_collections", name)
+		}
+	}
+	return nil
+}
+
 func contextFunc(ctx context.Context, r *http.Request) context.Context {
 	if authHeader := r.Header.Get("Authorization"); authHeader != "" {
 		return context.WithValue(ctx, authorizationKey, authHeader)
@@ -132,13 +300,41 @@ func (s *Server) registerTool(tool toolset.ServerTool) {
 			maps.Copy(params, args)
 		}
 
-		result, err := tool.Handler(nil, params)
-		return NewTextResult(result, err), nil
+		// Send progress notifications when the client asked for them
+		if meta := request.Params.Meta; meta != nil && meta.ProgressToken != nil {
+			ctx = handler.WithProgressReporter(ctx, s.progressReporter(ctx, meta.ProgressToken))
+		}
+
+		// Record the typed result so it is also returned as structured content
+		ctx, recorder := handler.WithResultRecorder(ctx)
+		result, err := tool.Handler(ctx, nil, params)
+		callResult := NewTextResult(result, err)
+		if err == nil {
+			callResult.StructuredContent = recorder.Value()
+			callResult.Content = append(callResult.Content, recorder.Contents()...)
+		}
+		return callResult, nil
 	}))
 	s.enabledTools = append(s.enabledTools, tool.Tool.Name)
 	logging.Info("Registered tool: %s", tool.Tool.Name)
 }
 
+// progressReporter sends the progress of a tool call as notifications/progress
+// for the progress token of its request
+func (s *Server) progressReporter(ctx context.Context, token mcp.ProgressToken) handler.ProgressReporter {
+	return func(done, total int, message string) {
+		err := s.server.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+			"progressToken": token,
+			"progress":      done,
+			"total":         total,
+			"message":       message,
+		})
+		if err != nil {
+			logging.Debug("Failed to send progress notification: %v", err)
+		}
+	}
+}
+
 // ServeStdio starts the MCP server in stdio mode
 func (s *Server) ServeStdio() error {
 	logging.Info("Starting MCP server in stdio mode")
@@ -167,7 +363,8 @@ func (s *Server) ServeHTTP(httpServer *http.Server) *server.StreamableHTTPServer
 	options := []server.StreamableHTTPOption{
 		server.WithHTTPContextFunc(contextFunc),
 		server.WithStreamableHTTPServer(httpServer),
-		server.WithStateLess(true),
+		// Keep sessions so that notifications reach HTTP clients
+		server.WithStateful(true),
 	}
 
 	return server.NewStreamableHTTPServer(s.server, options...)
@@ -181,6 +378,12 @@ func (s *Server) GetEnabledTools() []string {
 // Close cleans up the server resources
 func (s *Server) Close() {
 	logging.Info("Closing MCP server")
+	if s.janitor != nil {
+		s.janitor.Stop()
+	}
+	if s.watcher != nil {
+		s.watcher.Stop()
+	}
 }
 
 // NewTextResult creates a standardized text result for tool responses
@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/core/config"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+)
+
+func TestConfigureTool_Timeout(t *testing.T) {
+	_, s := newTestServer(t, config.StaticConfig{ToolTimeout: 60, ToolTimeouts: map[string]int{"slow": 1}})
+
+	var handlerErr error
+	slow := s.configureTool(toolset.ServerTool{
+		Tool: mcp.Tool{Name: "slow"},
+		Handler: func(ctx context.Context, _ interface{}, _ map[string]interface{}) (string, error) {
+			<-ctx.Done()
+			handlerErr = ctx.Err()
+			return "", handlerErr
+		},
+	}, s.toolsetClient)
+
+	start := time.Now()
+	_, err := slow.Handler(context.Background(), nil, map[string]interface{}{})
+	if !errors.Is(handlerErr, context.DeadlineExceeded) {
+		t.Errorf("Expected the handler to see the deadline, got %v", handlerErr)
+	}
+	if err == nil || !strings.Contains(err.Error(), "slow timed out after 1s") {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Expected the per-tool timeout to override tool_timeout, took %s", elapsed)
+	}
+}
+
+func TestConfigureTool_MaxTokens(t *testing.T) {
+	_, s := newTestServer(t, config.StaticConfig{MaxTokens: 500})
+
+	configure := func(properties map[string]any) func(map[string]interface{}) map[string]interface{} {
+		tool := s.configureTool(toolset.ServerTool{
+			Tool: mcp.Tool{Name: "tool", InputSchema: mcp.ToolInputSchema{Type: "object", Properties: properties}},
+			Handler: func(_ context.Context, _ interface{}, params map[string]interface{}) (string, error) {
+				return "", nil
+			},
+		}, s.toolsetClient)
+		return func(params map[string]interface{}) map[string]interface{} {
+			if _, err := tool.Handler(context.Background(), nil, params); err != nil {
+				t.Fatal(err)
+			}
+			return params
+		}
+	}
+
+	budgeted := configure(map[string]any{"max_tokens": map[string]any{"type": "number"}})
+	if got := budgeted(map[string]interface{}{})["max_tokens"]; got != 500 {
+		t.Errorf("Expected the default budget to be injected, got %v", got)
+	}
+	if got := budgeted(map[string]interface{}{"max_tokens": 20})["max_tokens"]; got != 20 {
+		t.Errorf("Expected an explicit budget to be kept, got %v", got)
+	}
+
+	unbudgeted := configure(map[string]any{"collection_name": map[string]any{"type": "string"}})
+	if _, ok := unbudgeted(map[string]interface{}{})["max_tokens"]; ok {
+		t.Error("Expected no budget for a tool without a max_tokens parameter")
+	}
+}
+
+func TestToolProgressNotifications(t *testing.T) {
+	backend, s := newTestServer(t, config.StaticConfig{})
+	backend.AddEntry("old", "a.md", "a")
+	backend.AddEntry("old", "b.md", "b")
+	session := newTestSession(t, s, "client")
+
+	call := func(meta, target string) {
+		t.Helper()
+		message := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"clone_collection",` + meta +
+			`"arguments":{"source_collection":"old","target_collection":"` + target + `"}}}`
+		ctx := s.server.WithContext(context.Background(), session)
+		response, err := json.Marshal(s.server.HandleMessage(ctx, []byte(message)))
+		if err != nil || strings.Contains(string(response), `"isError":true`) {
+			t.Fatalf("Clone failed: %s %v", response, err)
+		}
+	}
+
+	call(`"_meta":{"progressToken":"clone-1"},`, "new")
+	var progress []string
+	for _, n := range drainNotifications(session) {
+		if n.Method != "notifications/progress" {
+			continue
+		}
+		params := n.Params.AdditionalFields
+		if params["progressToken"] != "clone-1" || params["total"] != 2 {
+			t.Errorf("Unexpected progress parameters %v", params)
+		}
+		progress = append(progress, stringParam(params, "message"))
+	}
+	if len(progress) != 2 || !strings.HasPrefix(progress[1], "Copied b.md") {
+		t.Errorf("Expected progress per entry, got %v", progress)
+	}
+
+	call("", "other")
+	for _, n := range drainNotifications(session) {
+		if n.Method == "notifications/progress" {
+			t.Errorf("Expected no progress without a progress token, got %v", n.Params.AdditionalFields)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/core/config"
+)
+
+func TestStructuredContent_MatchesOutputSchema(t *testing.T) {
+	backend, s := newTestServer(t, config.StaticConfig{VersionStorePath: t.TempDir(), MaxVersions: 5})
+	backend.AddCollection("kb")
+	// Calls whose structured content does not match the tool's output schema
+	// fail with a validation error
+	server.WithOutputSchemaValidation()(s.server)
+	client := connect(t, s)
+
+	// One call per output shape, in an order where each finds what it needs
+	calls := []struct {
+		tool string
+		args map[string]any
+	}{
+		{"create_collection", map[string]any{"name": "docs"}},
+		{"list_collections", map[string]any{}},
+		{"add_document", map[string]any{"collection_name": "docs", "filename": "a.md", "file_content": "alpha beta\nsecond line\n"}},
+		{"add_document", map[string]any{"collection_name": "docs", "filename": "b.md", "file_content": "alpha gamma\n"}},
+		{"add_document", map[string]any{"collection_name": "docs", "filename": "a.md", "file_content": "alpha beta delta\nsecond line\n"}},
+		{"list_files", map[string]any{"collection_name": "docs"}},
+		{"search", map[string]any{"collection_name": "docs", "query": "alpha"}},
+		{"search", map[string]any{"collection_name": "docs", "query": "alpha", "max_tokens": 3}},
+		{"ask", map[string]any{"collection_name": "docs", "question": "alpha"}},
+		{"get_entry_content", map[string]any{"collection_name": "docs", "entry": "a.md"}},
+		{"get_entry_content", map[string]any{"collection_name": "docs", "entry": "a.md", "max_tokens": 2}},
+		{"get_entry_content", map[string]any{"collection_name": "docs", "entry": "a.md", "offset": 0, "limit": 5}},
+		{"grep_entries", map[string]any{"collection_name": "docs", "pattern": "alpha"}},
+		{"collection_stats", map[string]any{"collection_name": "docs"}},
+		{"find_duplicates", map[string]any{"collection_name": "docs"}},
+		{"remember", map[string]any{"collection_name": "docs", "content": "remember this", "tags": []any{"note"}}},
+		{"recall", map[string]any{"collection_name": "docs", "query": "remember"}},
+		{"forget", map[string]any{"collection_name": "docs", "tags": []any{"note"}}},
+		{"list_entry_versions", map[string]any{"collection_name": "docs", "entry": "a.md"}},
+		{"get_entry_version", map[string]any{"collection_name": "docs", "entry": "a.md", "version": 1}},
+		{"restore_entry_version", map[string]any{"collection_name": "docs", "entry": "a.md", "version": 1}},
+		{"diff_entries", map[string]any{"collection_name": "docs", "entry": "a.md", "other_entry": "b.md"}},
+		{"copy_entry", map[string]any{"collection_name": "docs", "entry": "a.md", "target_entry": "c.md"}},
+		{"move_entry", map[string]any{"collection_name": "docs", "entry": "c.md", "target_entry": "d.md"}},
+		{"copy_entries", map[string]any{"collection_name": "docs", "pattern": "*.md", "target_collection": "kb"}},
+		{"move_entries", map[string]any{"collection_name": "docs", "pattern": "d.md", "target_collection": "kb", "dry_run": true}},
+		{"compare_collections", map[string]any{"collection_a": "docs", "collection_b": "kb"}},
+		{"register_source", map[string]any{"collection_name": "docs", "url": "https://example.com/feed"}},
+		{"list_sources", map[string]any{"collection_name": "docs"}},
+		{"remove_source", map[string]any{"collection_name": "docs", "url": "https://example.com/feed"}},
+		{"clone_collection", map[string]any{"source_collection": "docs", "target_collection": "docs2"}},
+		{"rename_collection", map[string]any{"source_collection": "docs2", "target_collection": "docs3"}},
+		{"delete_entry", map[string]any{"collection_name": "docs", "entry": "d.md"}},
+		{"reset_collection", map[string]any{"name": "kb"}},
+	}
+
+	called := make(map[string]bool)
+	for _, call := range calls {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = call.tool
+		request.Params.Arguments = call.args
+		result, err := client.CallTool(context.Background(), request)
+		if err != nil {
+			t.Fatalf("%s failed: %v", call.tool, err)
+		}
+		if result.IsError {
+			t.Errorf("%s %v failed: %s", call.tool, call.args, mcp.GetTextFromContent(result.Content[0]))
+			continue
+		}
+		if result.StructuredContent == nil {
+			t.Errorf("%s returned no structured content", call.tool)
+		}
+		called[call.tool] = true
+	}
+
+	tools, err := client.ListTools(context.Background(), mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range tools.Tools {
+		if !called[tool.Name] {
+			t.Errorf("%s was not checked against its output schema", tool.Name)
+		}
+	}
+}
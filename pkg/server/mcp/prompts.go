@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/core/config"
+	"github.com/futuretea/localrecall-mcp-server/pkg/core/logging"
+)
+
+// collectionArgument is the prompt argument naming the collection to work
+// on. Under collection isolation it is hidden and set to the isolated
+// collection, like the collection_name tool parameter.
+const collectionArgument = "collection"
+
+// builtinPrompt is a prompt shipped with the server. It is only registered
+// when every tool its instructions name is enabled.
+type builtinPrompt struct {
+	config.PromptTemplate
+	requires []string
+}
+
+// collectionArg describes the collection argument of the built-in prompts
+var collectionArg = config.PromptArgument{
+	Name:        collectionArgument,
+	Description: "The LocalRecall collection to use",
+	Required:    true,
+}
+
+// builtinPrompts are the knowledge-base workflows offered as MCP prompts
+var builtinPrompts = []builtinPrompt{
+	{
+		PromptTemplate: config.PromptTemplate{
+			Name:        "answer_with_citations",
+			Description: "Answer a question from the knowledge base, citing the entries used",
+			Arguments: []config.PromptArgument{
+				{Name: "question", Description: "The question to answer", Required: true},
+				collectionArg,
+			},
+			Template: `Answer the following question using only the LocalRecall collection "{{.collection}}".
+
+Question: {{.question}}
+
+Use the search tool on the collection with format "markdown", which numbers each passage and names the entry it comes from. If the results do not cover the question, search again with rephrased or narrower queries. Base the answer only on the returned passages, cite every claim with the number of the passage supporting it, and end with the list of cited entries. If the collection does not contain the answer, say so rather than guessing.`,
+		},
+		requires: []string{"search"},
+	},
+	{
+		PromptTemplate: config.PromptTemplate{
+			Name:        "summarize_collection",
+			Description: "Summarize the contents of a collection",
+			Arguments: []config.PromptArgument{
+				collectionArg,
+				{Name: "focus", Description: "Optional topic to focus the summary on"},
+			},
+			Template: `Summarize the LocalRecall collection "{{.collection}}"{{if .focus}}, focusing on {{.focus}}{{end}}.
+
+Start with the collection_stats tool for an overview of its size and content types and list_files for its entries. {{if .focus}}Search the collection for "{{.focus}}" and read{{else}}Read{{end}} the most representative entries with get_entry_content. Describe the main topics covered, how the content is organized and any notable gaps, naming the entries each part of the summary is based on.`,
+		},
+		requires: []string{"collection_stats", "list_files", "get_entry_content", "search"},
+	},
+	{
+		PromptTemplate: config.PromptTemplate{
+			Name:        "ingest_note",
+			Description: "Store a piece of text as a note in the knowledge base",
+			Arguments: []config.PromptArgument{
+				{Name: "text", Description: "The text to store", Required: true},
+				collectionArg,
+				{Name: "title", Description: "Optional title for the note"},
+				{Name: "tags", Description: "Optional comma-separated tags for the note"},
+			},
+			Template: `Store the text below as a note in the LocalRecall collection "{{.collection}}".
+
+First use the recall tool to check whether the collection already holds an equivalent note; if it does, report that note instead of storing a duplicate. Otherwise store the text unchanged with the remember tool{{if .title}}, titled "{{.title}}"{{else}}, with a short descriptive title{{end}}{{if .tags}} and the tags {{.tags}}{{end}}, and report the id of the new note.
+
+Text:
+{{.text}}`,
+		},
+		requires: []string{"recall", "remember"},
+	},
+	{
+		PromptTemplate: config.PromptTemplate{
+			Name:        "audit_stale_docs",
+			Description: "Audit a collection for outdated, duplicated or conflicting documents",
+			Arguments: []config.PromptArgument{
+				collectionArg,
+				{Name: "query", Description: "Optional topic to restrict the audit to"},
+			},
+			Template: `Audit the LocalRecall collection "{{.collection}}" for stale documentation{{if .query}} about {{.query}}{{end}}.
+
+1. Get an overview of the entries with collection_stats and list_files{{if .query}}, and search the collection for "{{.query}}" to find the relevant ones{{end}}.
+2. Use find_duplicates to find near-duplicate entries that may be outdated copies of each other.
+3. Use grep_entries to look for signs of age, such as past years, old version numbers, "deprecated", "obsolete" or "TODO".
+4. Read suspicious entries with get_entry_content and compare overlapping ones with diff_entries.
+
+Report each stale, duplicated or conflicting entry with the evidence and a suggested action (update, merge or delete). Do not modify or delete anything.`,
+		},
+		requires: []string{"collection_stats", "list_files", "search", "find_duplicates", "grep_entries", "get_entry_content", "diff_entries"},
+	},
+}
+
+// registerPrompts registers the built-in prompts whose tools are enabled and
+// the prompt templates from the configuration. A configured prompt replaces
+// the built-in prompt of the same name.
+func (s *Server) registerPrompts() error {
+	count := 0
+	for _, p := range builtinPrompts {
+		if slices.ContainsFunc(s.configuration.Prompts, func(c config.PromptTemplate) bool { return c.Name == p.Name }) {
+			continue
+		}
+		if slices.ContainsFunc(p.requires, func(tool string) bool { return !slices.Contains(s.enabledTools, tool) }) {
+			logging.Debug("Skipping prompt %s: required tools are disabled", p.Name)
+			continue
+		}
+		if err := s.registerPrompt(p.PromptTemplate); err != nil {
+			return err
+		}
+		count++
+	}
+	for _, p := range s.configuration.Prompts {
+		if err := s.registerPrompt(p); err != nil {
+			return err
+		}
+		count++
+	}
+
+	logging.Info("Registered %d prompts", count)
+	return nil
+}
+
+// registerPrompt registers a prompt rendered from a template
+func (s *Server) registerPrompt(p config.PromptTemplate) error {
+	tmpl, err := p.Parse()
+	if err != nil {
+		return fmt.Errorf("prompt %s: %w", p.Name, err)
+	}
+	isolated := s.configuration.LocalRecallCollection
+
+	options := []mcp.PromptOption{mcp.WithPromptDescription(p.Description)}
+	for _, arg := range p.Arguments {
+		// Enforced isolation: do NOT expose the collection argument
+		if isolated != "" && arg.Name == collectionArgument {
+			continue
+		}
+		argOptions := []mcp.ArgumentOption{mcp.ArgumentDescription(arg.Description)}
+		if arg.Required {
+			argOptions = append(argOptions, mcp.RequiredArgument())
+		}
+		options = append(options, mcp.WithArgument(arg.Name, argOptions...))
+	}
+
+	s.server.AddPrompt(mcp.NewPrompt(p.Name, options...), func(_ context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		args := make(map[string]string, len(p.Arguments)+1)
+		for _, arg := range p.Arguments {
+			value := strings.TrimSpace(request.Params.Arguments[arg.Name])
+			if value == "" && arg.Required && !(isolated != "" && arg.Name == collectionArgument) {
+				return nil, fmt.Errorf("%s argument is required", arg.Name)
+			}
+			args[arg.Name] = value
+		}
+
+		if isolated != "" {
+			args[collectionArgument] = isolated
+		} else if collection := args[collectionArgument]; collection != "" && !s.collectionAllowed(collection) {
+			return nil, fmt.Errorf("collection %q is not in allowed_collections", collection)
+		}
+
+		var text strings.Builder
+		if err := tmpl.Execute(&text, args); err != nil {
+			return nil, fmt.Errorf("failed to render prompt %s: %w", p.Name, err)
+		}
+		return mcp.NewGetPromptResult(p.Description, []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text.String())),
+		}), nil
+	})
+	logging.Debug("Registered prompt: %s", p.Name)
+	return nil
+}
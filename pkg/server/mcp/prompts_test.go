@@ -0,0 +1,177 @@
+package mcp
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/core/config"
+	localrecallToolset "github.com/futuretea/localrecall-mcp-server/pkg/toolset/localrecall"
+)
+
+// promptNames lists the prompts offered to a client
+func promptNames(t *testing.T, client *mcpclient.Client) []string {
+	t.Helper()
+	result, err := client.ListPrompts(context.Background(), mcp.ListPromptsRequest{})
+	if err != nil {
+		t.Fatalf("Failed to list prompts: %v", err)
+	}
+	var names []string
+	for _, p := range result.Prompts {
+		names = append(names, p.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// getPrompt renders a prompt with arguments
+func getPrompt(client *mcpclient.Client, name string, args map[string]string) (string, error) {
+	request := mcp.GetPromptRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = args
+	result, err := client.GetPrompt(context.Background(), request)
+	if err != nil {
+		return "", err
+	}
+	return mcp.GetTextFromContent(result.Messages[0].Content), nil
+}
+
+func TestBuiltinPrompts_RequireReferencedTools(t *testing.T) {
+	var tools []string
+	for _, tool := range (&localrecallToolset.Toolset{}).GetTools(nil) {
+		tools = append(tools, tool.Tool.Name)
+	}
+
+	for _, p := range builtinPrompts {
+		for _, tool := range p.requires {
+			if !slices.Contains(tools, tool) {
+				t.Errorf("Prompt %s requires unknown tool %s", p.Name, tool)
+			}
+		}
+		for _, tool := range tools {
+			referenced := regexp.MustCompile(`\b` + tool + `\b`).MatchString(p.Template)
+			if referenced && !slices.Contains(p.requires, tool) {
+				t.Errorf("Prompt %s uses %s without requiring it", p.Name, tool)
+			}
+		}
+	}
+}
+
+func TestRegisterPrompts_EnabledTools(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.StaticConfig
+		want []string
+	}{
+		{
+			name: "all tools",
+			want: []string{"answer_with_citations", "audit_stale_docs", "ingest_note", "summarize_collection"},
+		},
+		{
+			name: "enabled tools",
+			cfg:  config.StaticConfig{EnabledTools: []string{"search", "remember"}},
+			want: []string{"answer_with_citations"},
+		},
+		{
+			name: "disabled tool",
+			cfg:  config.StaticConfig{DisabledTools: []string{"diff_entries"}},
+			want: []string{"answer_with_citations", "ingest_note", "summarize_collection"},
+		},
+		{
+			name: "configured prompt without tools",
+			cfg: config.StaticConfig{
+				EnabledTools: []string{"list_files"},
+				Prompts:      []config.PromptTemplate{{Name: "custom", Template: "Hello"}},
+			},
+			want: []string{"custom"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, s := newTestServer(t, tt.cfg)
+			if got := promptNames(t, connect(t, s)); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected prompts %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestConfiguredPrompts(t *testing.T) {
+	triage := config.PromptTemplate{
+		Name:        "triage_ticket",
+		Description: "Find articles for a ticket",
+		Arguments: []config.PromptArgument{
+			{Name: "ticket", Description: "The ticket text", Required: true},
+			{Name: "collection", Description: "The collection", Required: true},
+			{Name: "product", Description: "Optional product"},
+		},
+		Template: `Search "{{.collection}}" for: {{.ticket}}{{if .product}} ({{.product}}){{end}}`,
+	}
+	override := config.PromptTemplate{
+		Name:      "answer_with_citations",
+		Arguments: []config.PromptArgument{{Name: "question", Required: true}},
+		Template:  "Custom answer for {{.question}}",
+	}
+
+	cfg := config.StaticConfig{ListOutput: "json", Prompts: []config.PromptTemplate{triage, override}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected the prompts to validate, got %v", err)
+	}
+
+	t.Run("render", func(t *testing.T) {
+		_, s := newTestServer(t, cfg)
+		client := connect(t, s)
+
+		text, err := getPrompt(client, "triage_ticket", map[string]string{"ticket": "login fails", "collection": "kb"})
+		if err != nil || text != `Search "kb" for: login fails` {
+			t.Errorf("Unexpected rendering %q (%v)", text, err)
+		}
+		text, err = getPrompt(client, "triage_ticket", map[string]string{"ticket": "login fails", "collection": "kb", "product": "app"})
+		if err != nil || text != `Search "kb" for: login fails (app)` {
+			t.Errorf("Unexpected rendering %q (%v)", text, err)
+		}
+		if _, err := getPrompt(client, "triage_ticket", map[string]string{"collection": "kb"}); err == nil || !strings.Contains(err.Error(), "ticket argument is required") {
+			t.Errorf("Expected a missing required argument to fail, got %v", err)
+		}
+		if text, err := getPrompt(client, "answer_with_citations", map[string]string{"question": "why"}); err != nil || text != "Custom answer for why" {
+			t.Errorf("Expected the configured prompt to replace the built-in one, got %q (%v)", text, err)
+		}
+	})
+
+	t.Run("isolation", func(t *testing.T) {
+		isolated := cfg
+		isolated.LocalRecallCollection = "docs"
+		_, s := newTestServer(t, isolated)
+		client := connect(t, s)
+
+		result, err := client.ListPrompts(context.Background(), mcp.ListPromptsRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range result.Prompts {
+			for _, arg := range p.Arguments {
+				if arg.Name == collectionArgument {
+					t.Errorf("Expected the collection argument of %s to be hidden", p.Name)
+				}
+			}
+		}
+		text, err := getPrompt(client, "triage_ticket", map[string]string{"ticket": "login fails", "collection": "other"})
+		if err != nil || text != `Search "docs" for: login fails` {
+			t.Errorf("Expected the isolated collection to be used, got %q (%v)", text, err)
+		}
+	})
+
+	t.Run("allowed collections", func(t *testing.T) {
+		restricted := cfg
+		restricted.AllowedCollections = []string{"kb"}
+		_, s := newTestServer(t, restricted)
+		if _, err := getPrompt(connect(t, s), "triage_ticket", map[string]string{"ticket": "x", "collection": "other"}); err == nil {
+			t.Error("Expected a collection outside allowed_collections to be rejected")
+		}
+	})
+}
@@ -0,0 +1,388 @@
+package mcp
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/core/logging"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+)
+
+// collectionResource describes a collection and its external sources
+type collectionResource struct {
+	Name        string                   `json:"name"`
+	EntriesURI  string                   `json:"entries_uri"`
+	EntryCount  int                      `json:"entry_count"`
+	SourceCount int                      `json:"source_count"`
+	Sources     []map[string]interface{} `json:"sources"`
+}
+
+// entryListResource lists the entries of a collection
+type entryListResource struct {
+	Collection string              `json:"collection"`
+	Entries    []entryResourceLink `json:"entries"`
+	Count      int                 `json:"count"`
+}
+
+// entryResourceLink names an entry and its resource URI
+type entryResourceLink struct {
+	Entry string `json:"entry"`
+	URI   string `json:"uri"`
+}
+
+// collectionResourceLink names a collection and its resource URI
+type collectionResourceLink struct {
+	Name string `json:"name"`
+	URI  string `json:"uri"`
+}
+
+// collectionListResource lists the collections available to the client
+type collectionListResource struct {
+	Collections []collectionResourceLink `json:"collections"`
+	Count       int                      `json:"count"`
+}
+
+// resourcePageSize is the number of collection and entry resources listed per page
+const resourcePageSize = 100
+
+// registerResources registers the collection and entry resources. Under
+// collection isolation only the isolated collection is listed and readable.
+func (s *Server) registerResources(hooks *server.Hooks) {
+	s.server.AddResourceTemplate(
+		mcp.NewResourceTemplate(
+			toolset.ResourceScheme+"{collection}/entries/{entry}",
+			"LocalRecall entry",
+			mcp.WithTemplateDescription("The full content of an entry in a LocalRecall collection"),
+		),
+		s.readEntryResource,
+	)
+	s.server.AddResourceTemplate(
+		mcp.NewResourceTemplate(
+			toolset.ResourceScheme+"{collection}/entries",
+			"LocalRecall collection entries",
+			mcp.WithTemplateDescription("The entries of a LocalRecall collection with their resource URIs"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.readEntriesResource,
+	)
+	s.server.AddResourceTemplate(
+		mcp.NewResourceTemplate(
+			toolset.ResourceScheme+"{collection}",
+			"LocalRecall collection",
+			mcp.WithTemplateDescription("A LocalRecall collection: entry count and registered external sources"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.readCollectionResource,
+	)
+
+	if isolated := s.configuration.LocalRecallCollection; isolated != "" {
+		s.server.AddResource(
+			mcp.NewResource(
+				toolset.CollectionURI(isolated),
+				isolated,
+				mcp.WithResourceDescription("The LocalRecall collection "+isolated+" and its external sources"),
+				mcp.WithMIMEType("application/json"),
+			),
+			s.readCollectionResource,
+		)
+		s.server.AddResource(
+			mcp.NewResource(
+				toolset.EntriesURI(isolated),
+				isolated+" entries",
+				mcp.WithResourceDescription("The entries of the LocalRecall collection "+isolated),
+				mcp.WithMIMEType("application/json"),
+			),
+			s.readEntriesResource,
+		)
+	} else {
+		s.server.AddResource(
+			mcp.NewResource(
+				toolset.CollectionsURI,
+				"LocalRecall collections",
+				mcp.WithResourceDescription("All LocalRecall collections with their resource URIs"),
+				mcp.WithMIMEType("application/json"),
+			),
+			s.readCollectionsResource,
+		)
+	}
+	s.listEntryResources(hooks)
+	logging.Info("Registered collection and entry resources")
+}
+
+// resourceCursor is the position of a listed collection or entry resource. A
+// collection has an empty entry, so it sorts before its entries.
+type resourceCursor struct {
+	collection string
+	entry      string
+}
+
+// compare orders cursors by collection, then entry
+func (c resourceCursor) compare(other resourceCursor) int {
+	return cmp.Or(cmp.Compare(c.collection, other.collection), cmp.Compare(c.entry, other.entry))
+}
+
+// encode returns the cursor as an opaque pagination cursor
+func (c resourceCursor) encode() mcp.Cursor {
+	return mcp.Cursor(base64.StdEncoding.EncodeToString([]byte(c.collection + "\x00" + c.entry)))
+}
+
+// decodeResourceCursor parses a pagination cursor returned by encode
+func decodeResourceCursor(cursor mcp.Cursor) (resourceCursor, bool) {
+	data, err := base64.StdEncoding.DecodeString(string(cursor))
+	if err != nil {
+		return resourceCursor{}, false
+	}
+	collection, entry, _ := strings.Cut(string(data), "\x00")
+	return resourceCursor{collection: collection, entry: entry}, true
+}
+
+// listEntryResources adds the accessible collections and their entries to
+// resources/list, a page at a time. The static resources make up the start of
+// the first page: the cursor of later pages is taken from the request before
+// the server lists them, and they are dropped from those pages.
+func (s *Server) listEntryResources(hooks *server.Hooks) {
+	var mu sync.Mutex
+	cursors := make(map[*mcp.ListResourcesRequest]resourceCursor)
+
+	hooks.AddBeforeListResources(func(_ context.Context, _ any, request *mcp.ListResourcesRequest) {
+		if request.Params.Cursor == "" {
+			return
+		}
+		cursor, ok := decodeResourceCursor(request.Params.Cursor)
+		if !ok {
+			// Let the server reject the malformed cursor
+			return
+		}
+		request.Params.Cursor = ""
+		mu.Lock()
+		cursors[request] = cursor
+		mu.Unlock()
+	})
+	hooks.AddAfterListResources(func(ctx context.Context, _ any, request *mcp.ListResourcesRequest, result *mcp.ListResourcesResult) {
+		mu.Lock()
+		cursor, continued := cursors[request]
+		delete(cursors, request)
+		mu.Unlock()
+		if continued {
+			result.Resources = []mcp.Resource{}
+		}
+
+		resources, next, err := s.entryResources(ctx, cursor)
+		if err != nil {
+			logging.Warn("Failed to list entry resources: %v", err)
+		}
+		result.Resources = append(result.Resources, resources...)
+		result.NextCursor = next
+	})
+}
+
+// entryResources returns a page of collection and entry resources after a
+// cursor, and the cursor of the next page if there is one. Under collection
+// isolation the collection itself is a static resource, so only its entries
+// are listed.
+func (s *Server) entryResources(ctx context.Context, after resourceCursor) ([]mcp.Resource, mcp.Cursor, error) {
+	isolated := s.configuration.LocalRecallCollection
+	collections := []string{isolated}
+	if isolated == "" {
+		list, err := s.localRecallClient.ListCollections(ctx)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list collections: %w", err)
+		}
+		collections = slices.DeleteFunc(slices.Sorted(slices.Values(list.Collections)), func(name string) bool {
+			return !s.collectionAllowed(name)
+		})
+	}
+
+	var resources []mcp.Resource
+	var last resourceCursor
+	// add appends a resource after the cursor, reporting false when the page is full
+	add := func(position resourceCursor, resource mcp.Resource) bool {
+		if position.compare(after) <= 0 {
+			return true
+		}
+		if len(resources) == resourcePageSize {
+			return false
+		}
+		resources = append(resources, resource)
+		last = position
+		return true
+	}
+
+	for _, collection := range collections {
+		if collection < after.collection {
+			continue
+		}
+		if isolated == "" {
+			resource := mcp.NewResource(
+				toolset.CollectionURI(collection),
+				collection,
+				mcp.WithResourceDescription("The LocalRecall collection "+collection+" and its external sources"),
+				mcp.WithMIMEType("application/json"),
+			)
+			if !add(resourceCursor{collection: collection}, resource) {
+				return resources, last.encode(), nil
+			}
+		}
+
+		files, err := s.localRecallClient.ListFiles(ctx, collection)
+		if err != nil {
+			return resources, "", fmt.Errorf("failed to list entries of %s: %w", collection, err)
+		}
+		for _, entry := range slices.Sorted(slices.Values(files.Entries)) {
+			resource := mcp.NewResource(
+				toolset.EntryURI(collection, entry),
+				entry,
+				mcp.WithResourceDescription("An entry of the LocalRecall collection "+collection),
+				mcp.WithMIMEType(toolset.EntryMIMEType(entry)),
+			)
+			if !add(resourceCursor{collection: collection, entry: entry}, resource) {
+				return resources, last.encode(), nil
+			}
+		}
+	}
+	return resources, "", nil
+}
+
+// collectionAllowed reports whether a collection may be accessed under the
+// isolation and allowlist settings
+func (s *Server) collectionAllowed(collection string) bool {
+	if isolated := s.configuration.LocalRecallCollection; isolated != "" && collection != isolated {
+		return false
+	}
+	allowed := s.configuration.AllowedCollections
+	return len(allowed) == 0 || slices.Contains(allowed, collection)
+}
+
+// resourceCollection resolves the collection of a resource request
+func (s *Server) resourceCollection(request mcp.ReadResourceRequest) (string, error) {
+	collection := resourceArgument(request, "collection")
+	if collection == "" {
+		// Static resources carry no template arguments
+		collection = s.configuration.LocalRecallCollection
+	}
+	if collection == "" || !s.collectionAllowed(collection) {
+		return "", fmt.Errorf("collection %q is not accessible", collection)
+	}
+	return collection, nil
+}
+
+// resourceArgument returns an unescaped URI template argument
+func resourceArgument(request mcp.ReadResourceRequest, name string) string {
+	var value string
+	switch v := request.Params.Arguments[name].(type) {
+	case string:
+		value = v
+	case []string:
+		if len(v) > 0 {
+			value = v[0]
+		}
+	}
+	if unescaped, err := url.PathUnescape(value); err == nil {
+		return unescaped
+	}
+	return value
+}
+
+// jsonResource returns data as a JSON resource
+func jsonResource(uri string, data interface{}) ([]mcp.ResourceContents, error) {
+	text, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal resource: %w", err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: uri, MIMEType: "application/json", Text: string(text)},
+	}, nil
+}
+
+// readEntryResource returns the content of an entry
+func (s *Server) readEntryResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	collection, err := s.resourceCollection(request)
+	if err != nil {
+		return nil, err
+	}
+	entry := resourceArgument(request, "entry")
+
+	content, err := s.toolsetClient.GetEntryContent(ctx, collection, entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read entry %s: %w", entry, err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: toolset.EntryMIMEType(entry),
+			Text:     content.Content,
+		},
+	}, nil
+}
+
+// readEntriesResource lists the entries of a collection
+func (s *Server) readEntriesResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	collection, err := s.resourceCollection(request)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := s.localRecallClient.ListFiles(ctx, collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entries of %s: %w", collection, err)
+	}
+	list := entryListResource{Collection: collection, Entries: []entryResourceLink{}, Count: len(files.Entries)}
+	for _, entry := range files.Entries {
+		list.Entries = append(list.Entries, entryResourceLink{Entry: entry, URI: toolset.EntryURI(collection, entry)})
+	}
+	return jsonResource(request.Params.URI, list)
+}
+
+// readCollectionResource describes a collection and its sources
+func (s *Server) readCollectionResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	collection, err := s.resourceCollection(request)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := s.localRecallClient.ListFiles(ctx, collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entries of %s: %w", collection, err)
+	}
+	sources, err := s.localRecallClient.ListSources(ctx, collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sources of %s: %w", collection, err)
+	}
+	desc := collectionResource{
+		Name:        collection,
+		EntriesURI:  toolset.EntriesURI(collection),
+		EntryCount:  len(files.Entries),
+		SourceCount: len(sources.Sources),
+		Sources:     sources.Sources,
+	}
+	if desc.Sources == nil {
+		desc.Sources = []map[string]interface{}{}
+	}
+	return jsonResource(request.Params.URI, desc)
+}
+
+// readCollectionsResource lists the accessible collections
+func (s *Server) readCollectionsResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	collections, err := s.localRecallClient.ListCollections(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	list := collectionListResource{Collections: []collectionResourceLink{}}
+	for _, name := range collections.Collections {
+		if s.collectionAllowed(name) {
+			list.Collections = append(list.Collections, collectionResourceLink{Name: name, URI: toolset.CollectionURI(name)})
+		}
+	}
+	list.Count = len(list.Collections)
+	return jsonResource(request.Params.URI, list)
+}
@@ -0,0 +1,239 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/core/config"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+)
+
+// listResourceURIs lists all resources page by page, returning their URIs and the number of pages
+func listResourceURIs(t *testing.T, client *mcpclient.Client) ([]string, int) {
+	t.Helper()
+	var uris []string
+	request := mcp.ListResourcesRequest{}
+	for pages := 1; ; pages++ {
+		result, err := client.ListResourcesByPage(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Failed to list resources: %v", err)
+		}
+		for _, resource := range result.Resources {
+			uris = append(uris, resource.URI)
+		}
+		if result.NextCursor == "" {
+			return uris, pages
+		}
+		request.Params.Cursor = result.NextCursor
+	}
+}
+
+func TestReadResources(t *testing.T) {
+	backend, s := newTestServer(t, config.StaticConfig{})
+	backend.AddEntry("docs", "guide.md", "# Guide")
+	backend.AddEntry("docs", "notes/a b.txt", "spaced")
+	backend.AddSource("docs", "https://example.com/feed", 60)
+	backend.AddCollection("empty")
+	client := connect(t, s)
+
+	t.Run("entry", func(t *testing.T) {
+		request := mcp.ReadResourceRequest{}
+		request.Params.URI = toolset.EntryURI("docs", "guide.md")
+		result, err := client.ReadResource(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Failed to read entry: %v", err)
+		}
+		text := result.Contents[0].(mcp.TextResourceContents)
+		if text.Text != "# Guide" || text.MIMEType != "text/markdown" {
+			t.Errorf("Expected the markdown entry, got %q (%s)", text.Text, text.MIMEType)
+		}
+		if got := readResourceText(t, client, toolset.EntryURI("docs", "notes/a b.txt")); got != "spaced" {
+			t.Errorf("Expected the escaped entry name to be read, got %q", got)
+		}
+	})
+
+	t.Run("entries", func(t *testing.T) {
+		var list entryListResource
+		if err := json.Unmarshal([]byte(readResourceText(t, client, toolset.EntriesURI("docs"))), &list); err != nil {
+			t.Fatal(err)
+		}
+		if list.Collection != "docs" || list.Count != 2 || list.Entries[1].URI != toolset.EntryURI("docs", "notes/a b.txt") {
+			t.Errorf("Unexpected entry list %+v", list)
+		}
+	})
+
+	t.Run("collection", func(t *testing.T) {
+		var desc collectionResource
+		if err := json.Unmarshal([]byte(readResourceText(t, client, toolset.CollectionURI("docs"))), &desc); err != nil {
+			t.Fatal(err)
+		}
+		if desc.EntryCount != 2 || desc.SourceCount != 1 || desc.EntriesURI != toolset.EntriesURI("docs") {
+			t.Errorf("Unexpected collection %+v", desc)
+		}
+	})
+
+	t.Run("collections", func(t *testing.T) {
+		var list collectionListResource
+		if err := json.Unmarshal([]byte(readResourceText(t, client, toolset.CollectionsURI)), &list); err != nil {
+			t.Fatal(err)
+		}
+		if list.Count != 2 || list.Collections[0].Name != "docs" || list.Collections[1].URI != toolset.CollectionURI("empty") {
+			t.Errorf("Unexpected collection list %+v", list)
+		}
+	})
+
+	t.Run("missing entry", func(t *testing.T) {
+		request := mcp.ReadResourceRequest{}
+		request.Params.URI = toolset.EntryURI("docs", "missing.md")
+		if _, err := client.ReadResource(context.Background(), request); err == nil {
+			t.Error("Expected reading a missing entry to fail")
+		}
+	})
+}
+
+func TestReadResources_Isolation(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        config.StaticConfig
+		readable   []string
+		unreadable []string
+	}{
+		{
+			name:       "isolated collection",
+			cfg:        config.StaticConfig{LocalRecallCollection: "docs"},
+			readable:   []string{toolset.CollectionURI("docs"), toolset.EntriesURI("docs"), toolset.EntryURI("docs", "a.md")},
+			unreadable: []string{toolset.CollectionURI("other"), toolset.EntriesURI("other"), toolset.EntryURI("other", "b.md")},
+		},
+		{
+			name:       "allowed collections",
+			cfg:        config.StaticConfig{AllowedCollections: []string{"docs"}},
+			readable:   []string{toolset.CollectionsURI, toolset.CollectionURI("docs"), toolset.EntryURI("docs", "a.md")},
+			unreadable: []string{toolset.CollectionURI("other"), toolset.EntryURI("other", "b.md")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend, s := newTestServer(t, tt.cfg)
+			backend.AddEntry("docs", "a.md", "a")
+			backend.AddEntry("other", "b.md", "b")
+			client := connect(t, s)
+
+			for _, uri := range tt.readable {
+				readResourceText(t, client, uri)
+			}
+			for _, uri := range tt.unreadable {
+				request := mcp.ReadResourceRequest{}
+				request.Params.URI = uri
+				if _, err := client.ReadResource(context.Background(), request); err == nil {
+					t.Errorf("Expected %s not to be readable", uri)
+				}
+			}
+		})
+	}
+
+	t.Run("collections list", func(t *testing.T) {
+		backend, s := newTestServer(t, config.StaticConfig{AllowedCollections: []string{"docs"}})
+		backend.AddEntry("docs", "a.md", "a")
+		backend.AddEntry("other", "b.md", "b")
+		var list collectionListResource
+		if err := json.Unmarshal([]byte(readResourceText(t, connect(t, s), toolset.CollectionsURI)), &list); err != nil {
+			t.Fatal(err)
+		}
+		if list.Count != 1 || list.Collections[0].Name != "docs" {
+			t.Errorf("Expected only the allowed collection, got %+v", list)
+		}
+	})
+}
+
+func TestListResources(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.StaticConfig
+		want []string
+	}{
+		{
+			name: "all collections",
+			cfg:  config.StaticConfig{},
+			want: []string{
+				toolset.CollectionsURI,
+				toolset.CollectionURI("docs"), toolset.EntryURI("docs", "a.md"), toolset.EntryURI("docs", "b.md"),
+				toolset.CollectionURI("other"), toolset.EntryURI("other", "c.md"),
+			},
+		},
+		{
+			name: "allowed collections",
+			cfg:  config.StaticConfig{AllowedCollections: []string{"other"}},
+			want: []string{toolset.CollectionsURI, toolset.CollectionURI("other"), toolset.EntryURI("other", "c.md")},
+		},
+		{
+			name: "isolated collection",
+			cfg:  config.StaticConfig{LocalRecallCollection: "docs"},
+			want: []string{
+				toolset.CollectionURI("docs"), toolset.EntriesURI("docs"),
+				toolset.EntryURI("docs", "a.md"), toolset.EntryURI("docs", "b.md"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend, s := newTestServer(t, tt.cfg)
+			backend.AddEntry("docs", "b.md", "b")
+			backend.AddEntry("docs", "a.md", "a")
+			backend.AddEntry("other", "c.md", "c")
+
+			uris, pages := listResourceURIs(t, connect(t, s))
+			slices.Sort(uris)
+			want := slices.Sorted(slices.Values(tt.want))
+			if !slices.Equal(uris, want) {
+				t.Errorf("Expected resources %v, got %v", want, uris)
+			}
+			if pages != 1 {
+				t.Errorf("Expected one page, got %d", pages)
+			}
+		})
+	}
+}
+
+func TestListResources_Pagination(t *testing.T) {
+	backend, s := newTestServer(t, config.StaticConfig{})
+	// 2 collections and 2*resourcePageSize entries fill two pages and start a third
+	for i := range resourcePageSize {
+		backend.AddEntry("docs", fmt.Sprintf("%03d.md", i), "doc")
+		backend.AddEntry("notes", fmt.Sprintf("%03d.md", i), "note")
+	}
+
+	uris, pages := listResourceURIs(t, connect(t, s))
+	// The static resource is listed before the first page of collections and entries
+	if len(uris) != 2*resourcePageSize+3 {
+		t.Errorf("Expected %d resources, got %d", 2*resourcePageSize+3, len(uris))
+	}
+	if pages != 3 {
+		t.Errorf("Expected 3 pages, got %d", pages)
+	}
+	seen := make(map[string]bool)
+	for _, uri := range uris {
+		if seen[uri] {
+			t.Errorf("Expected %s to be listed once", uri)
+		}
+		seen[uri] = true
+	}
+	for _, uri := range []string{toolset.CollectionsURI, toolset.CollectionURI("notes"), toolset.EntryURI("notes", "099.md")} {
+		if !seen[uri] {
+			t.Errorf("Expected %s to be listed", uri)
+		}
+	}
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		request := mcp.ListResourcesRequest{}
+		request.Params.Cursor = "not base64!"
+		if _, err := connect(t, s).ListResourcesByPage(context.Background(), request); err == nil {
+			t.Error("Expected an invalid cursor to be rejected")
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+)
+
+// sample asks the model of the client that made the current request to
+// generate a message. Clients that did not declare the sampling capability,
+// and requests without a session, are not asked.
+func (s *Server) sample(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo)
+	if !ok || session.GetClientCapabilities().Sampling == nil {
+		return nil, toolset.ErrSamplingUnsupported
+	}
+	return s.server.RequestSampling(ctx, request)
+}
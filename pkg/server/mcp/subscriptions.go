@@ -0,0 +1,342 @@
+package mcp
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/client"
+	"github.com/futuretea/localrecall-mcp-server/pkg/core/logging"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+	"github.com/futuretea/localrecall-mcp-server/pkg/versions"
+)
+
+// subscriptionWatcher tracks the resources each session has subscribed to and
+// notifies the session when they change. Changes are detected by polling
+// LocalRecall and by re-checking collections after tool calls made through
+// this server.
+type subscriptionWatcher struct {
+	client   *client.Client
+	server   *server.MCPServer
+	allowed  func(collection string) bool
+	interval time.Duration
+
+	mu            sync.Mutex
+	subscriptions map[string]map[string]struct{} // session ID -> subscribed URIs
+
+	// checkMu serializes checks and guards the snapshots they compare against
+	checkMu     sync.Mutex
+	collections []string            // last listed accessible collections, nil until listed
+	entries     map[string][]string // collection -> last listed entries
+	hashes      map[string]string   // entry URI -> last content hash ("" if missing)
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// newSubscriptionWatcher creates a watcher polling every interval (0 = only
+// check after tool calls)
+func newSubscriptionWatcher(c *client.Client, s *server.MCPServer, allowed func(string) bool, interval time.Duration) *subscriptionWatcher {
+	return &subscriptionWatcher{
+		client:        c,
+		server:        s,
+		allowed:       allowed,
+		interval:      interval,
+		subscriptions: make(map[string]map[string]struct{}),
+		entries:       make(map[string][]string),
+		hashes:        make(map[string]string),
+	}
+}
+
+// watchSubscriptions records subscriptions through the server hooks and starts
+// polling for changes
+func (s *Server) watchSubscriptions(hooks *server.Hooks) {
+	interval := time.Duration(s.configuration.SubscriptionPollInterval) * time.Second
+	s.watcher = newSubscriptionWatcher(s.localRecallClient, s.server, s.collectionAllowed, interval)
+
+	hooks.AddAfterSubscribe(func(ctx context.Context, _ any, request *mcp.SubscribeRequest, _ *mcp.EmptyResult) {
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			s.watcher.Subscribe(ctx, session.SessionID(), request.Params.URI)
+		}
+	})
+	hooks.AddAfterUnsubscribe(func(ctx context.Context, _ any, request *mcp.UnsubscribeRequest, _ *mcp.EmptyResult) {
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			s.watcher.Unsubscribe(session.SessionID(), request.Params.URI)
+		}
+	})
+	hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+		s.watcher.RemoveSession(session.SessionID())
+	})
+
+	s.watcher.Start(context.Background())
+}
+
+// Subscribe records a subscription and takes a baseline of the resource so
+// that later checks can detect changes
+func (w *subscriptionWatcher) Subscribe(ctx context.Context, sessionID, uri string) {
+	w.mu.Lock()
+	if w.subscriptions[sessionID] == nil {
+		w.subscriptions[sessionID] = make(map[string]struct{})
+	}
+	w.subscriptions[sessionID][uri] = struct{}{}
+	w.mu.Unlock()
+	logging.Debug("Session %s subscribed to %s", sessionID, uri)
+
+	if collection, _, ok := toolset.ParseResourceURI(uri); ok {
+		w.Check(ctx, []string{collection})
+	} else if uri == toolset.CollectionsURI {
+		w.Check(ctx, []string{})
+	}
+}
+
+// Unsubscribe removes a subscription
+func (w *subscriptionWatcher) Unsubscribe(sessionID, uri string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.subscriptions[sessionID], uri)
+	if len(w.subscriptions[sessionID]) == 0 {
+		delete(w.subscriptions, sessionID)
+	}
+}
+
+// RemoveSession removes all subscriptions of a closed session
+func (w *subscriptionWatcher) RemoveSession(sessionID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.subscriptions, sessionID)
+}
+
+// subscribed returns every subscribed URI
+func (w *subscriptionWatcher) subscribed() map[string]struct{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	uris := make(map[string]struct{})
+	for _, subs := range w.subscriptions {
+		maps.Copy(uris, subs)
+	}
+	return uris
+}
+
+// Watches reports whether any subscription depends on one of the collections
+func (w *subscriptionWatcher) Watches(collections []string) bool {
+	for uri := range w.subscribed() {
+		if uri == toolset.CollectionsURI {
+			return true
+		}
+		if collection, _, ok := toolset.ParseResourceURI(uri); ok && slices.Contains(collections, collection) {
+			return true
+		}
+	}
+	return false
+}
+
+// Start polls for changes in a background goroutine
+func (w *subscriptionWatcher) Start(ctx context.Context) {
+	if w.interval <= 0 {
+		return
+	}
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.Check(ctx, nil)
+			}
+		}
+	}()
+	logging.Info("Resource subscription poller started (interval: %s)", w.interval)
+}
+
+// Stop stops polling and waits for an in-progress check to finish
+func (w *subscriptionWatcher) Stop() {
+	w.once.Do(func() {
+		if w.cancel == nil {
+			return
+		}
+		w.cancel()
+		<-w.done
+	})
+}
+
+// Check compares the subscribed resources of the given collections (all
+// subscribed collections if nil) against the last snapshot and notifies the
+// subscribers of changed resources. Adding or removing entries updates the
+// collection and its entry list; only adding or removing an accessible
+// collection changes the resource list. Resources seen for the first time
+// only record a baseline.
+func (w *subscriptionWatcher) Check(ctx context.Context, collections []string) {
+	subscribed := w.subscribed()
+
+	w.checkMu.Lock()
+	defer w.checkMu.Unlock()
+
+	// Group subscribed entries by collection, skipping inaccessible ones
+	watched := make(map[string][]string)
+	for uri := range subscribed {
+		collection, entry, ok := toolset.ParseResourceURI(uri)
+		if !ok || !w.allowed(collection) {
+			continue
+		}
+		if _, seen := watched[collection]; !seen {
+			watched[collection] = nil
+		}
+		if entry != "" {
+			watched[collection] = append(watched[collection], entry)
+		}
+	}
+	w.prune(watched, subscribed)
+	if len(subscribed) == 0 {
+		return
+	}
+
+	updated := make(map[string]struct{})
+
+	listChanged := w.checkCollections(ctx)
+	if listChanged {
+		updated[toolset.CollectionsURI] = struct{}{}
+	}
+
+	for collection, entries := range watched {
+		if collections != nil && !slices.Contains(collections, collection) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		changed := w.checkEntries(ctx, collection)
+		if len(changed) > 0 {
+			updated[toolset.CollectionURI(collection)] = struct{}{}
+			updated[toolset.EntriesURI(collection)] = struct{}{}
+			for _, entry := range changed {
+				updated[toolset.EntryURI(collection, entry)] = struct{}{}
+			}
+		}
+		for _, entry := range entries {
+			if w.checkContent(ctx, collection, entry) {
+				updated[toolset.EntryURI(collection, entry)] = struct{}{}
+			}
+		}
+	}
+
+	w.notify(updated, listChanged)
+}
+
+// prune drops snapshots no subscription depends on any more
+func (w *subscriptionWatcher) prune(watched map[string][]string, subscribed map[string]struct{}) {
+	if len(subscribed) == 0 {
+		w.collections = nil
+	}
+	for collection := range w.entries {
+		if _, ok := watched[collection]; !ok {
+			delete(w.entries, collection)
+		}
+	}
+	for uri := range w.hashes {
+		if _, ok := subscribed[uri]; !ok {
+			delete(w.hashes, uri)
+		}
+	}
+}
+
+// checkCollections reports whether the set of accessible collections changed
+func (w *subscriptionWatcher) checkCollections(ctx context.Context) bool {
+	result, err := w.client.ListCollections(ctx)
+	if err != nil {
+		logging.Debug("Subscription check failed to list collections: %v", err)
+		return false
+	}
+	current := slices.DeleteFunc(slices.Sorted(slices.Values(result.Collections)), func(collection string) bool {
+		return !w.allowed(collection)
+	})
+	if current == nil {
+		// Keep the baseline of a server without collections
+		current = []string{}
+	}
+	previous := w.collections
+	w.collections = current
+	return previous != nil && !slices.Equal(previous, current)
+}
+
+// checkEntries returns the entries added to or removed from a collection
+func (w *subscriptionWatcher) checkEntries(ctx context.Context, collection string) []string {
+	var current []string
+	result, err := w.client.ListFiles(ctx, collection)
+	if err != nil {
+		// The collection may have been removed; treat it as empty
+		logging.Debug("Subscription check failed to list entries of %s: %v", collection, err)
+	} else {
+		current = slices.Sorted(slices.Values(result.Entries))
+	}
+	previous, seen := w.entries[collection]
+	w.entries[collection] = current
+	if !seen {
+		return nil
+	}
+
+	var changed []string
+	for _, entry := range previous {
+		if !slices.Contains(current, entry) {
+			changed = append(changed, entry)
+		}
+	}
+	for _, entry := range current {
+		if !slices.Contains(previous, entry) {
+			changed = append(changed, entry)
+		}
+	}
+	return changed
+}
+
+// checkContent reports whether the content of a subscribed entry changed.
+// A missing entry hashes to "".
+func (w *subscriptionWatcher) checkContent(ctx context.Context, collection, entry string) bool {
+	hash := ""
+	if content, err := w.client.GetEntryContent(ctx, collection, entry); err == nil {
+		hash = versions.Hash(content.Content)
+	}
+	uri := toolset.EntryURI(collection, entry)
+	previous, seen := w.hashes[uri]
+	w.hashes[uri] = hash
+	return seen && previous != hash
+}
+
+// notify sends resources/updated to every session subscribed to an updated
+// resource, and resources/list_changed to all sessions if listChanged is set
+func (w *subscriptionWatcher) notify(updated map[string]struct{}, listChanged bool) {
+	w.mu.Lock()
+	targets := make(map[string][]string)
+	for sessionID, subs := range w.subscriptions {
+		for uri := range subs {
+			if _, ok := updated[uri]; ok {
+				targets[sessionID] = append(targets[sessionID], uri)
+			}
+		}
+	}
+	w.mu.Unlock()
+
+	for sessionID, uris := range targets {
+		for _, uri := range uris {
+			err := w.server.SendNotificationToSpecificClient(sessionID, mcp.MethodNotificationResourceUpdated, map[string]any{"uri": uri})
+			if err != nil {
+				logging.Debug("Failed to notify session %s of %s: %v", sessionID, uri, err)
+			}
+		}
+	}
+	if listChanged {
+		w.server.SendNotificationToAllClients(mcp.MethodNotificationResourcesListChanged, nil)
+	}
+}
@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/core/config"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+)
+
+// received returns the notifications sent to a session so far as sorted
+// "method uri" strings
+func (s *testSession) received() []string {
+	var got []string
+	for _, n := range drainNotifications(s) {
+		got = append(got, n.Method+" "+stringParam(n.Params.AdditionalFields, "uri"))
+	}
+	slices.Sort(got)
+	return got
+}
+
+const (
+	updated     = mcp.MethodNotificationResourceUpdated + " "
+	listChanged = mcp.MethodNotificationResourcesListChanged + " "
+)
+
+func TestSubscriptions(t *testing.T) {
+	ctx := context.Background()
+	backend, s := newTestServer(t, config.StaticConfig{})
+	backend.AddEntry("docs", "a.md", "a")
+	backend.AddEntry("docs", "b.md", "b")
+	backend.AddEntry("other", "c.md", "c")
+
+	subscriber := newTestSession(t, s, "subscriber")
+	bystander := newTestSession(t, s, "bystander")
+	s.watcher.Subscribe(ctx, subscriber.id, toolset.EntriesURI("docs"))
+	s.watcher.Subscribe(ctx, subscriber.id, toolset.EntryURI("docs", "a.md"))
+
+	t.Run("unchanged", func(t *testing.T) {
+		s.watcher.Check(ctx, nil)
+		if got := subscriber.received(); len(got) != 0 {
+			t.Errorf("Expected no notifications, got %v", got)
+		}
+	})
+
+	t.Run("entry edited", func(t *testing.T) {
+		backend.AddEntry("docs", "a.md", "edited")
+		s.watcher.Check(ctx, nil)
+		if got, want := subscriber.received(), []string{updated + toolset.EntryURI("docs", "a.md")}; !slices.Equal(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+		if got := bystander.received(); len(got) != 0 {
+			t.Errorf("Expected no notifications for the bystander, got %v", got)
+		}
+	})
+
+	t.Run("entry added", func(t *testing.T) {
+		backend.AddEntry("docs", "new.md", "new")
+		s.watcher.Check(ctx, nil)
+		if got, want := subscriber.received(), []string{updated + toolset.EntriesURI("docs")}; !slices.Equal(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+		if got := bystander.received(); len(got) != 0 {
+			t.Errorf("Expected adding an entry not to change the resource list, got %v", got)
+		}
+	})
+
+	t.Run("unwatched collection", func(t *testing.T) {
+		backend.AddEntry("other", "c.md", "edited")
+		backend.AddEntry("other", "d.md", "d")
+		s.watcher.Check(ctx, nil)
+		if got := subscriber.received(); len(got) != 0 {
+			t.Errorf("Expected no notifications, got %v", got)
+		}
+	})
+
+	t.Run("collection added", func(t *testing.T) {
+		backend.AddCollection("fresh")
+		s.watcher.Check(ctx, []string{"docs"})
+		if got, want := subscriber.received(), []string{listChanged}; !slices.Equal(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+		if got, want := bystander.received(), []string{listChanged}; !slices.Equal(got, want) {
+			t.Errorf("Expected %v for the bystander, got %v", want, got)
+		}
+	})
+
+	t.Run("unsubscribed", func(t *testing.T) {
+		s.watcher.Unsubscribe(subscriber.id, toolset.EntryURI("docs", "a.md"))
+		backend.AddEntry("docs", "a.md", "edited again")
+		s.watcher.Check(ctx, nil)
+		if got := subscriber.received(); len(got) != 0 {
+			t.Errorf("Expected no notifications after unsubscribing, got %v", got)
+		}
+	})
+}
+
+func TestSubscriptions_CollectionsURI(t *testing.T) {
+	ctx := context.Background()
+	backend, s := newTestServer(t, config.StaticConfig{AllowedCollections: []string{"docs", "notes"}})
+	backend.AddCollection("docs")
+	subscriber := newTestSession(t, s, "subscriber")
+	s.watcher.Subscribe(ctx, subscriber.id, toolset.CollectionsURI)
+
+	backend.AddCollection("hidden")
+	s.watcher.Check(ctx, nil)
+	if got := subscriber.received(); len(got) != 0 {
+		t.Errorf("Expected an inaccessible collection to be ignored, got %v", got)
+	}
+
+	backend.AddCollection("notes")
+	s.watcher.Check(ctx, nil)
+	if got, want := subscriber.received(), []string{listChanged, updated + toolset.CollectionsURI}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
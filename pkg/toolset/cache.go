@@ -0,0 +1,84 @@
+package toolset
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache is a concurrency-safe key-value cache whose entries expire after a fixed TTL.
+// A nil *Cache is valid and never stores anything.
+type Cache[V any] struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	items map[string]cacheItem[V]
+}
+
+type cacheItem[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// NewCache creates a cache with the given TTL. A TTL of zero or less disables caching.
+func NewCache[V any](ttl time.Duration) *Cache[V] {
+	if ttl <= 0 {
+		return nil
+	}
+	return &Cache[V]{
+		ttl:   ttl,
+		items: make(map[string]cacheItem[V]),
+	}
+}
+
+// Get returns the cached value for key if present and not expired
+func (c *Cache[V]) Get(key string) (V, bool) {
+	var zero V
+	if c == nil {
+		return zero, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+	if time.Now().After(item.expiresAt) {
+		delete(c.items, key)
+		return zero, false
+	}
+	return item.value, true
+}
+
+// Set stores a value under key
+func (c *Cache[V]) Set(key string, value V) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = cacheItem[V]{value: value, expiresAt: time.Now().Add(c.ttl)}
+}
+
+// Delete removes key from the cache
+func (c *Cache[V]) Delete(key string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, key)
+}
+
+// DeletePrefix removes all keys starting with prefix
+func (c *Cache[V]) DeletePrefix(prefix string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.items, key)
+		}
+	}
+}
@@ -1,10 +1,96 @@
 package toolset
 
 import (
+	"context"
+	"errors"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
 	"github.com/futuretea/localrecall-mcp-server/pkg/client"
+	"github.com/futuretea/localrecall-mcp-server/pkg/expiry"
+	"github.com/futuretea/localrecall-mcp-server/pkg/versions"
 )
 
+// DefaultScanConcurrency is the number of entries fetched in parallel by scanning tools
+const DefaultScanConcurrency = 4
+
+// ErrSamplingUnsupported is returned by a Sampler when the connected client
+// does not support sampling
+var ErrSamplingUnsupported = errors.New("the client does not support sampling")
+
+// Sampler asks the model of the connected MCP client to generate a message
+type Sampler func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error)
+
 // LocalRecallClient wraps the LocalRecall API client for use in toolset
 type LocalRecallClient struct {
 	Client *client.Client
+
+	// Contents caches entry contents fetched by tools that scan whole collections
+	Contents *Cache[*client.EntryContent]
+
+	// Computed caches results derived from whole collections, such as statistics
+	Computed *Cache[any]
+
+	// Expiry records entries scheduled for deletion (nil = expiry disabled)
+	Expiry *expiry.Index
+
+	// Versions archives prior revisions of updated entries (nil = versioning disabled)
+	Versions *versions.Store
+
+	// ScanConcurrency limits parallel entry fetches (0 = DefaultScanConcurrency)
+	ScanConcurrency int
+
+	// Sample requests messages from the client's model (nil = sampling unavailable, e.g. from the CLI)
+	Sample Sampler
+}
+
+// contentKey returns the cache key for an entry
+func contentKey(collection, entry string) string {
+	return collection + "/" + entry
+}
+
+// GetEntryContent returns the content of an entry, served from the cache when possible
+func (c *LocalRecallClient) GetEntryContent(ctx context.Context, collection, entry string) (*client.EntryContent, error) {
+	key := contentKey(collection, entry)
+	if content, ok := c.Contents.Get(key); ok {
+		return content, nil
+	}
+	content, err := c.Client.GetEntryContent(ctx, collection, entry)
+	if err != nil {
+		return nil, err
+	}
+	c.Contents.Set(key, content)
+	return content, nil
+}
+
+// InvalidateEntry drops the cached content of an entry after it changes,
+// along with any results computed from its collection
+func (c *LocalRecallClient) InvalidateEntry(collection, entry string) {
+	c.Contents.Delete(contentKey(collection, entry))
+	c.InvalidateComputed(collection)
+}
+
+// InvalidateCollection drops all cached data of a collection
+func (c *LocalRecallClient) InvalidateCollection(collection string) {
+	c.Contents.DeletePrefix(contentKey(collection, ""))
+	c.InvalidateComputed(collection)
+}
+
+// InvalidateComputed drops the results computed from a collection after a
+// change that leaves its entries as they are, such as a source being added
+func (c *LocalRecallClient) InvalidateComputed(collection string) {
+	c.Computed.DeletePrefix(contentKey(collection, ""))
+}
+
+// ComputedKey returns the cache key for a result computed from a collection
+func ComputedKey(collection, name string) string {
+	return contentKey(collection, name)
+}
+
+// Concurrency returns the effective scan concurrency
+func (c *LocalRecallClient) Concurrency() int {
+	if c.ScanConcurrency > 0 {
+		return c.ScanConcurrency
+	}
+	return DefaultScanConcurrency
 }
@@ -25,11 +25,31 @@ func FormatYAML(data interface{}) (string, error) {
 	return string(yamlBytes), nil
 }
 
+// MarkdownRenderer is implemented by results that have a dedicated Markdown rendering
+type MarkdownRenderer interface {
+	Markdown() string
+}
+
+// FormatMarkdown formats data as Markdown. Data without a dedicated rendering
+// is emitted as a fenced JSON block.
+func FormatMarkdown(data interface{}) (string, error) {
+	if r, ok := data.(MarkdownRenderer); ok {
+		return r.Markdown(), nil
+	}
+	jsonStr, err := FormatJSON(data)
+	if err != nil {
+		return "", err
+	}
+	return "```json\n" + jsonStr + "\n```\n", nil
+}
+
 // FormatOutput formats data according to the specified format
 func FormatOutput(data interface{}, format string) (string, error) {
 	switch format {
 	case "yaml":
 		return FormatYAML(data)
+	case "markdown":
+		return FormatMarkdown(data)
 	case "json":
 		return FormatJSON(data)
 	default:
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+)
+
+// rendered has a dedicated Markdown rendering
+type rendered struct {
+	Name string `json:"name" yaml:"name"`
+}
+
+func (r rendered) Markdown() string {
+	return "# " + r.Name + "\n"
+}
+
+func TestFormatOutput(t *testing.T) {
+	data := map[string]interface{}{"name": "docs"}
+	tests := []struct {
+		name   string
+		data   interface{}
+		format string
+		want   string
+	}{
+		{name: "json", data: data, format: "json", want: "{\n  \"name\": \"docs\"\n}"},
+		{name: "default", data: data, format: "", want: "{\n  \"name\": \"docs\"\n}"},
+		{name: "unknown", data: data, format: "table", want: "{\n  \"name\": \"docs\"\n}"},
+		{name: "yaml", data: data, format: "yaml", want: "name: docs\n"},
+		{name: "markdown renderer", data: rendered{Name: "docs"}, format: "markdown", want: "# docs\n"},
+		{name: "markdown fallback", data: data, format: "markdown", want: "```json\n{\n  \"name\": \"docs\"\n}\n```\n"},
+		{name: "renderer as json", data: rendered{Name: "docs"}, format: "json", want: "{\n  \"name\": \"docs\"\n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatOutput(tt.data, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("FormatOutput(%q) = %q, want %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatOutput_Unmarshalable(t *testing.T) {
+	for _, format := range []string{"json", "markdown"} {
+		if _, err := FormatOutput(make(chan int), format); err == nil || !strings.Contains(err.Error(), "failed to marshal JSON") {
+			t.Errorf("Expected a marshal error for %s, got %v", format, err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"fmt"
+	"strings"
 )
 
 // GetStringParam extracts a string parameter from the params map
@@ -95,3 +96,52 @@ func RequireStringParam(params map[string]interface{}, key string) (string, erro
 	}
 	return strVal, nil
 }
+
+// GetBoolParam extracts a boolean parameter from the params map
+func GetBoolParam(params map[string]interface{}, key string, defaultValue bool) bool {
+	if val, ok := params[key]; ok {
+		switch v := val.(type) {
+		case bool:
+			return v
+		case string:
+			switch strings.ToLower(v) {
+			case "true", "1", "yes":
+				return true
+			case "false", "0", "no":
+				return false
+			}
+		}
+	}
+	return defaultValue
+}
+
+// GetStringSliceParam extracts a []string parameter from the params map.
+// JSON arrays of strings and comma-separated strings are accepted.
+func GetStringSliceParam(params map[string]interface{}, key string) []string {
+	val, ok := params[key]
+	if !ok {
+		return nil
+	}
+	var result []string
+	switch v := val.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+				result = append(result, strings.TrimSpace(s))
+			}
+		}
+	case []string:
+		for _, s := range v {
+			if strings.TrimSpace(s) != "" {
+				result = append(result, strings.TrimSpace(s))
+			}
+		}
+	case string:
+		for _, s := range strings.Split(v, ",") {
+			if strings.TrimSpace(s) != "" {
+				result = append(result, strings.TrimSpace(s))
+			}
+		}
+	}
+	return result
+}
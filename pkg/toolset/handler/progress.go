@@ -0,0 +1,25 @@
+package handler
+
+import "context"
+
+// progressReporterKey is the context key of the ProgressReporter
+type progressReporterKey struct{}
+
+// ProgressReporter receives the progress of a long-running handler: done of
+// total items processed, with a human-readable message
+type ProgressReporter func(done, total int, message string)
+
+// WithProgressReporter returns a context whose handlers report their progress
+// to report
+func WithProgressReporter(ctx context.Context, report ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressReporterKey{}, report)
+}
+
+// ReportProgress reports the progress of the handler run with ctx. It is a
+// no-op when ctx has no reporter, e.g. when the client did not ask for
+// progress or a handler runs from the CLI.
+func ReportProgress(ctx context.Context, done, total int, message string) {
+	if report, ok := ctx.Value(progressReporterKey{}).(ProgressReporter); ok {
+		report(done, total, message)
+	}
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// resultRecorderKey is the context key of the ResultRecorder
+type resultRecorderKey struct{}
+
+// ResultRecorder keeps the typed result a handler formatted, so that the MCP
+// server can return it as structured content next to the text rendering, and
+// any content the handler returns in addition to the text
+type ResultRecorder struct {
+	value    interface{}
+	contents []mcp.Content
+}
+
+// WithResultRecorder returns a context recording the results formatted by
+// handlers run with it
+func WithResultRecorder(ctx context.Context) (context.Context, *ResultRecorder) {
+	r := &ResultRecorder{}
+	return context.WithValue(ctx, resultRecorderKey{}, r), r
+}
+
+// Value returns the last recorded result, or nil if none was recorded
+func (r *ResultRecorder) Value() interface{} {
+	return r.value
+}
+
+// Contents returns the additional content recorded by the handler
+func (r *ResultRecorder) Contents() []mcp.Content {
+	return r.contents
+}
+
+// RecordResult records data as the result of the handler run with ctx. It is
+// a no-op when ctx has no recorder, e.g. when a handler runs from the CLI.
+func RecordResult(ctx context.Context, data interface{}) {
+	if r, ok := ctx.Value(resultRecorderKey{}).(*ResultRecorder); ok {
+		r.value = data
+	}
+}
+
+// FormatResult records data as the handler result and formats it according to
+// the specified format
+func FormatResult(ctx context.Context, data interface{}, format string) (string, error) {
+	RecordResult(ctx, data)
+	return FormatOutput(data, format)
+}
+
+// RecordContent records content to return after the text rendering, such as
+// resource links. It is a no-op when ctx has no recorder.
+func RecordContent(ctx context.Context, contents ...mcp.Content) {
+	if r, ok := ctx.Value(resultRecorderKey{}).(*ResultRecorder); ok {
+		r.contents = append(r.contents, contents...)
+	}
+}
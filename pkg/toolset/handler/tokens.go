@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"sync"
+	"unicode/utf8"
+)
+
+// charsPerToken is the average number of characters per token assumed by EstimateTokens
+const charsPerToken = 4
+
+// TokenEstimator returns the approximate number of tokens in a piece of text
+type TokenEstimator func(text string) int
+
+var (
+	estimatorMu sync.RWMutex
+	estimator   TokenEstimator = EstimateTokens
+)
+
+// EstimateTokens approximates the token count of text at roughly four
+// characters per token, which is close enough for budgeting LLM context.
+func EstimateTokens(text string) int {
+	n := utf8.RuneCountInString(text)
+	return (n + charsPerToken - 1) / charsPerToken
+}
+
+// SetTokenEstimator replaces the estimator used by CountTokens.
+// Passing nil restores EstimateTokens.
+func SetTokenEstimator(e TokenEstimator) {
+	estimatorMu.Lock()
+	defer estimatorMu.Unlock()
+	if e == nil {
+		e = EstimateTokens
+	}
+	estimator = e
+}
+
+// CountTokens returns the approximate token count of text using the configured estimator
+func CountTokens(text string) int {
+	estimatorMu.RLock()
+	defer estimatorMu.RUnlock()
+	return estimator(text)
+}
+
+// TruncateToTokens shortens text so that it fits within maxTokens, returning
+// the truncated text and the number of bytes removed. Text is cut on a
+// character boundary.
+func TruncateToTokens(text string, maxTokens int) (string, int) {
+	if maxTokens <= 0 {
+		return "", len(text)
+	}
+	if CountTokens(text) <= maxTokens {
+		return text, 0
+	}
+
+	// Binary search the longest prefix that fits
+	lo, hi := 0, len(text)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if CountTokens(prefix(text, mid)) <= maxTokens {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	kept := prefix(text, lo)
+	return kept, len(text) - len(kept)
+}
+
+// prefix returns the longest prefix of s of at most n bytes that does not split a character
+func prefix(s string, n int) string {
+	for n > 0 && n < len(s) && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{text: "", want: 0},
+		{text: "abc", want: 1},
+		{text: "abcd", want: 1},
+		{text: "abcde", want: 2},
+		{text: "日本語の", want: 1},
+	}
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateToTokens(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		maxTokens   int
+		wantKept    string
+		wantRemoved int
+	}{
+		{name: "fits", text: "hello world", maxTokens: 3, wantKept: "hello world", wantRemoved: 0},
+		{name: "no budget", text: "hello world", maxTokens: 0, wantKept: "", wantRemoved: 11},
+		{name: "cut", text: "hello world", maxTokens: 2, wantKept: "hello wo", wantRemoved: 3},
+		// Each character is three bytes, so a byte cut must back up to a character start
+		{name: "multibyte", text: "日本語のテキストです", maxTokens: 1, wantKept: "日本語の", wantRemoved: 18},
+		{name: "mixed widths", text: "aé日🙂aé日🙂", maxTokens: 1, wantKept: "aé日🙂", wantRemoved: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, removed := TruncateToTokens(tt.text, tt.maxTokens)
+			if kept != tt.wantKept || removed != tt.wantRemoved {
+				t.Errorf("Expected (%q, %d), got (%q, %d)", tt.wantKept, tt.wantRemoved, kept, removed)
+			}
+			if !utf8.ValidString(kept) {
+				t.Errorf("Expected valid UTF-8, got %q", kept)
+			}
+			if len(kept)+removed != len(tt.text) {
+				t.Errorf("Expected kept and removed bytes to add up to %d, got %d", len(tt.text), len(kept)+removed)
+			}
+		})
+	}
+}
+
+func TestTruncateToTokens_CustomEstimator(t *testing.T) {
+	// Count one token per word
+	SetTokenEstimator(func(text string) int { return len(strings.Fields(text)) })
+	defer SetTokenEstimator(nil)
+
+	kept, removed := TruncateToTokens("one two three four", 2)
+	if kept != "one two " || removed != len("three four") {
+		t.Errorf("Expected (%q, %d), got (%q, %d)", "one two ", len("three four"), kept, removed)
+	}
+}
@@ -0,0 +1,154 @@
+package localrecall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	lrclient "github.com/futuretea/localrecall-mcp-server/pkg/client"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
+)
+
+const (
+	// defaultAnswerMaxTokens is the default length limit of a sampled answer
+	defaultAnswerMaxTokens = 1024
+	// askSystemPrompt instructs the client's model to answer from the passages only
+	askSystemPrompt = "You answer questions using only the numbered passages provided from a knowledge base. " +
+		"Cite every claim with the number of the passage supporting it, such as [1] or [2][3]. " +
+		"If the passages do not contain the answer, say so instead of guessing. Do not use outside knowledge."
+)
+
+// askResult is the response of ask: a sampled answer citing the matching
+// passages, or the passages alone when no answer could be sampled
+type askResult struct {
+	Question   string        `json:"question" yaml:"question"`
+	Collection string        `json:"collection,omitempty" yaml:"collection,omitempty"`
+	Answer     string        `json:"answer,omitempty" yaml:"answer,omitempty"`
+	Model      string        `json:"model,omitempty" yaml:"model,omitempty"`
+	Sampled    bool          `json:"sampled" yaml:"sampled"`
+	Notice     string        `json:"notice,omitempty" yaml:"notice,omitempty"`
+	Sources    []citedSource `json:"sources" yaml:"sources"`
+
+	cited *citedResults
+}
+
+// Markdown renders the answer followed by its sources, or the passages with
+// the reason no answer was sampled
+func (r *askResult) Markdown() string {
+	var b strings.Builder
+	if !r.Sampled {
+		if r.Notice != "" {
+			fmt.Fprintf(&b, "_%s_\n\n", r.Notice)
+		}
+		b.WriteString(r.cited.Markdown())
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "## Answer\n\n%s\n\n", strings.TrimSpace(r.Answer))
+	writeSourceList(&b, r.Collection, r.Sources)
+	return b.String()
+}
+
+// AskHandler answers a question from the hits of a search, using MCP sampling
+// to have the client's model write an answer citing the hits. When the client
+// does not support sampling, the hits are returned for the caller to answer from.
+func AskHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
+	client, err := getClient(clientInterface)
+	if err != nil {
+		return "", err
+	}
+
+	question, err := handler.RequireStringParam(params, "question")
+	if err != nil {
+		return "", err
+	}
+
+	collectionName := handler.GetStringParam(params, "collection_name", "")
+	maxResults := handler.GetIntParam(params, "max_results", 5)
+	answerMaxTokens := handler.GetIntParam(params, "answer_max_tokens", defaultAnswerMaxTokens)
+	format := handler.GetStringParam(params, "format", "json")
+	if answerMaxTokens <= 0 {
+		return "", fmt.Errorf("answer_max_tokens must be positive")
+	}
+
+	var opts *lrclient.SearchOptions
+	if minSim := handler.GetFloat64Param(params, "min_similarity", 0); minSim > 0 {
+		opts = &lrclient.SearchOptions{MinSimilarity: minSim}
+	}
+
+	hits, err := client.Client.SearchWithOptions(ctx, collectionName, question, maxResults, opts)
+	if err != nil {
+		return "", fmt.Errorf("search failed: %w", err)
+	}
+
+	cited := newCitedResults(collectionName, hits)
+	result := &askResult{
+		Question:   question,
+		Collection: collectionName,
+		Sources:    cited.Sources,
+		cited:      cited,
+	}
+
+	switch {
+	case len(hits.Results) == 0:
+		result.Notice = "No matching entries found, so no answer was generated."
+	case client.Sample == nil:
+		result.Notice = samplingFallbackNotice(toolset.ErrSamplingUnsupported)
+	default:
+		answer, err := client.Sample(ctx, newAskRequest(question, cited, hits, answerMaxTokens))
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", fmt.Errorf("ask failed: %w", err)
+			}
+			result.Notice = samplingFallbackNotice(err)
+			break
+		}
+		result.Answer = mcp.GetTextFromContent(answer.Content)
+		result.Model = answer.Model
+		result.Sampled = true
+	}
+
+	return handler.FormatResult(ctx, result, format)
+}
+
+// samplingFallbackNotice explains why the passages are returned without an answer
+func samplingFallbackNotice(err error) string {
+	if errors.Is(err, toolset.ErrSamplingUnsupported) {
+		return "The client does not support sampling; answer the question from the passages below."
+	}
+	return fmt.Sprintf("Sampling failed (%v); answer the question from the passages below.", err)
+}
+
+// newAskRequest builds the sampling request asking for an answer from the
+// hits, numbered per source entry like the citations of the result
+func newAskRequest(question string, cited *citedResults, hits *lrclient.SearchResult, maxTokens int) mcp.CreateMessageRequest {
+	citations := make(map[string]int)
+	for _, src := range cited.Sources {
+		citations[src.Entry] = src.Citation
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Question: %s\n\nPassages:\n", question)
+	for _, hit := range hits.Results {
+		source := hitSource(hit)
+		if source == "" {
+			source = unknownSource
+		}
+		fmt.Fprintf(&b, "\n[%d] %s\n%s\n", citations[source], source, strings.TrimSpace(hitText(hit)))
+	}
+
+	return mcp.CreateMessageRequest{
+		CreateMessageParams: mcp.CreateMessageParams{
+			Messages: []mcp.SamplingMessage{
+				{Role: mcp.RoleUser, Content: mcp.NewTextContent(b.String())},
+			},
+			SystemPrompt:   askSystemPrompt,
+			IncludeContext: "none",
+			MaxTokens:      maxTokens,
+		},
+	}
+}
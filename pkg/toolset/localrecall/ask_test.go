@@ -0,0 +1,86 @@
+package localrecall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+)
+
+func TestAskHandler(t *testing.T) {
+	server, client := newTestClient(t)
+	server.AddEntry("docs", "a.md", "The sky is blue.")
+	server.AddEntry("docs", "b.md", "Grass is green.")
+	params := map[string]interface{}{"collection_name": "docs", "question": "sky"}
+
+	t.Run("no sampler", func(t *testing.T) {
+		result := callToolJSON[askResult](t, client, AskHandler, params)
+		if result.Sampled || result.Answer != "" || !strings.Contains(result.Notice, "does not support sampling") {
+			t.Errorf("Expected the passages without an answer, got %+v", result)
+		}
+		if len(result.Sources) != 1 || result.Sources[0].Entry != "a.md" {
+			t.Errorf("Expected the matching passage, got %+v", result.Sources)
+		}
+
+		markdown, err := callTool(client, AskHandler, map[string]interface{}{"collection_name": "docs", "question": "sky", "format": "markdown"})
+		if err != nil || !strings.HasPrefix(markdown, "_The client does not support sampling") || !strings.Contains(markdown, "### [1] a.md") {
+			t.Errorf("Expected the notice followed by the passages, got %q (%v)", markdown, err)
+		}
+	})
+
+	t.Run("sampled", func(t *testing.T) {
+		var request mcp.CreateMessageRequest
+		sampling := *client
+		sampling.Sample = func(_ context.Context, r mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			request = r
+			return &mcp.CreateMessageResult{
+				SamplingMessage: mcp.SamplingMessage{Role: mcp.RoleAssistant, Content: mcp.NewTextContent("It is blue [1].")},
+				Model:           "test-model",
+			}, nil
+		}
+		result := callToolJSON[askResult](t, &sampling, AskHandler, map[string]interface{}{"collection_name": "docs", "question": "sky", "answer_max_tokens": 50})
+		if !result.Sampled || result.Answer != "It is blue [1]." || result.Model != "test-model" || result.Notice != "" {
+			t.Errorf("Expected the sampled answer, got %+v", result)
+		}
+		if request.MaxTokens != 50 || !strings.Contains(fmt.Sprint(request.Messages), "The sky is blue.") {
+			t.Errorf("Expected the passages in the sampling request, got %+v", request.CreateMessageParams)
+		}
+	})
+
+	t.Run("sampling fails", func(t *testing.T) {
+		for _, sampleErr := range []error{toolset.ErrSamplingUnsupported, errors.New("user rejected")} {
+			failing := *client
+			failing.Sample = func(context.Context, mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+				return nil, sampleErr
+			}
+			result := callToolJSON[askResult](t, &failing, AskHandler, params)
+			if result.Sampled || result.Notice != samplingFallbackNotice(sampleErr) || len(result.Sources) != 1 {
+				t.Errorf("Expected the passages with the fallback notice for %v, got %+v", sampleErr, result)
+			}
+		}
+	})
+
+	t.Run("no hits", func(t *testing.T) {
+		sampled := false
+		sampling := *client
+		sampling.Sample = func(context.Context, mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			sampled = true
+			return nil, errors.New("unexpected")
+		}
+		result := callToolJSON[askResult](t, &sampling, AskHandler, map[string]interface{}{"collection_name": "docs", "question": "purple"})
+		if sampled || result.Sampled || !strings.Contains(result.Notice, "No matching entries") || len(result.Sources) != 0 {
+			t.Errorf("Expected no sampling without hits, got %+v", result)
+		}
+	})
+
+	t.Run("invalid answer_max_tokens", func(t *testing.T) {
+		if _, err := callTool(client, AskHandler, map[string]interface{}{"collection_name": "docs", "question": "sky", "answer_max_tokens": 0}); err == nil {
+			t.Error("Expected answer_max_tokens 0 to be rejected")
+		}
+	})
+}
@@ -0,0 +1,202 @@
+package localrecall
+
+import (
+	"context"
+	"fmt"
+
+	lrclient "github.com/futuretea/localrecall-mcp-server/pkg/client"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
+)
+
+const (
+	// truncationMarker is appended to text shortened to fit the token budget
+	truncationMarker = "\n…[truncated %d bytes]"
+	// minTrimTokens stops halving fields once they are this small
+	minTrimTokens = 16
+)
+
+// budgetNotice reports what was left out of a response to fit max_tokens
+type budgetNotice struct {
+	MaxTokens       int      `json:"max_tokens" yaml:"max_tokens"`
+	OmittedResults  int      `json:"omitted_results,omitempty" yaml:"omitted_results,omitempty"`
+	OmittedIDs      []string `json:"omitted_ids,omitempty" yaml:"omitted_ids,omitempty"`
+	TruncatedFields int      `json:"truncated_fields,omitempty" yaml:"truncated_fields,omitempty"`
+	TruncatedBytes  int      `json:"truncated_bytes,omitempty" yaml:"truncated_bytes,omitempty"`
+	Hint            string   `json:"hint" yaml:"hint"`
+}
+
+// budgetedSearchResult is a search result annotated with a budget notice
+type budgetedSearchResult struct {
+	lrclient.SearchResult `yaml:",inline"`
+	Budget                *budgetNotice `json:"budget,omitempty" yaml:"budget,omitempty"`
+}
+
+// budgetedEntryContent is an entry annotated with a budget notice
+type budgetedEntryContent struct {
+	lrclient.EntryContent `yaml:",inline"`
+	Budget                *budgetNotice `json:"budget,omitempty" yaml:"budget,omitempty"`
+}
+
+// fitSearchResult renders a search result within maxTokens. Lowest-scoring
+// hits are dropped first; if a single hit still does not fit, its longest
+// text fields are halved until the output fits. With embedHits, the text of
+// the hits is also returned as embedded resources and counts against the
+// budget a second time.
+func fitSearchResult(ctx context.Context, result *lrclient.SearchResult, collection, format string, maxTokens int, embedHits bool) (string, error) {
+	var notice *budgetNotice
+	size := func(out string) int {
+		n := handler.CountTokens(out)
+		if embedHits {
+			n += embeddedTokens(result.Results)
+		}
+		return n
+	}
+	render := func() (string, error) {
+		budgeted := &budgetedSearchResult{SearchResult: *result, Budget: notice}
+		if format == "markdown" {
+			// The numbered citations are a rendering; the structured result stays the same
+			handler.RecordResult(ctx, budgeted)
+			cited := newCitedResults(collection, result)
+			cited.Budget = notice
+			return handler.FormatOutput(cited, format)
+		}
+		return handler.FormatResult(ctx, budgeted, format)
+	}
+
+	out, err := render()
+	if err != nil || maxTokens <= 0 || size(out) <= maxTokens {
+		return out, err
+	}
+
+	notice = &budgetNotice{
+		MaxTokens: maxTokens,
+		Hint:      fmt.Sprintf("Output was reduced to fit max_tokens=%d. Request a larger max_tokens, lower max_results, or use get_entry_content to read a source in full.", maxTokens),
+	}
+	if out, err = render(); err != nil {
+		return "", err
+	}
+
+	// Drop the lowest-scoring hits while more than one remains
+	for len(result.Results) > 1 && size(out) > maxTokens {
+		i := lowestScoring(result.Results)
+		if id, ok := result.Results[i]["ID"].(string); ok {
+			notice.OmittedIDs = append(notice.OmittedIDs, id)
+		}
+		result.Results = append(result.Results[:i:i], result.Results[i+1:]...)
+		result.Count = len(result.Results)
+		notice.OmittedResults++
+		if out, err = render(); err != nil {
+			return "", err
+		}
+	}
+
+	// Halve the longest remaining text field until the output fits. Fields are
+	// always cut from their original text so markers do not nest.
+	trimmed := make(map[fieldRef]*trimmedField)
+	for size(out) > maxTokens {
+		ref, ok := longestField(result.Results)
+		if !ok {
+			break
+		}
+		hit := result.Results[ref.index]
+		f, seen := trimmed[ref]
+		if !seen {
+			original := hit[ref.key].(string)
+			f = &trimmedField{original: original, limit: handler.CountTokens(original)}
+			trimmed[ref] = f
+		}
+		if f.limit <= minTrimTokens {
+			break
+		}
+		f.limit /= 2
+		kept, removed := handler.TruncateToTokens(f.original, f.limit)
+		hit[ref.key] = kept + fmt.Sprintf(truncationMarker, removed)
+		f.removed = removed
+		if out, err = render(); err != nil {
+			return "", err
+		}
+	}
+	for _, f := range trimmed {
+		notice.TruncatedFields++
+		notice.TruncatedBytes += f.removed
+	}
+
+	return render()
+}
+
+// fieldRef identifies a text field of a hit by position
+type fieldRef struct {
+	index int
+	key   string
+}
+
+// trimmedField tracks a hit field shortened to fit the token budget
+type trimmedField struct {
+	original string
+	limit    int
+	removed  int
+}
+
+// lowestScoring returns the index of the hit with the lowest similarity,
+// preferring the later hit on ties
+func lowestScoring(hits []map[string]interface{}) int {
+	lowest := 0
+	for i, hit := range hits {
+		if hitSimilarity(hit) <= hitSimilarity(hits[lowest]) {
+			lowest = i
+		}
+	}
+	return lowest
+}
+
+// longestField returns the position of the longest Content or Context field
+func longestField(hits []map[string]interface{}) (fieldRef, bool) {
+	var best fieldRef
+	bestLen := 0
+	for i, hit := range hits {
+		for _, key := range []string{"Content", "Context"} {
+			if s, ok := hit[key].(string); ok && len(s) > bestLen {
+				best, bestLen = fieldRef{index: i, key: key}, len(s)
+			}
+		}
+	}
+	return best, bestLen > 0
+}
+
+// fitEntryContent renders an entry within maxTokens, truncating its content
+// and reporting how much was left out.
+func fitEntryContent(ctx context.Context, entry *lrclient.EntryContent, format string, maxTokens int) (string, error) {
+	out, err := handler.FormatResult(ctx, entry, format)
+	if err != nil || maxTokens <= 0 || handler.CountTokens(out) <= maxTokens {
+		return out, err
+	}
+
+	notice := &budgetNotice{MaxTokens: maxTokens}
+	result := &budgetedEntryContent{EntryContent: *entry, Budget: notice}
+	notice.Hint = fmt.Sprintf("Entry content was truncated to fit max_tokens=%d. Request a larger max_tokens, or use offset and limit to page through the entry.", maxTokens)
+
+	// Reserve room for the envelope and the notice, then fill the rest with
+	// content, shrinking further if escaping pushes the output over budget
+	result.Content = ""
+	envelope, err := handler.FormatOutput(result, format)
+	if err != nil {
+		return "", err
+	}
+	allowance := maxTokens - handler.CountTokens(envelope) - handler.CountTokens(fmt.Sprintf(truncationMarker, len(entry.Content)))
+	for {
+		kept, removed := handler.TruncateToTokens(entry.Content, allowance)
+		notice.TruncatedFields = 1
+		notice.TruncatedBytes = removed
+		result.Content = kept + fmt.Sprintf(truncationMarker, removed)
+
+		out, err = handler.FormatResult(ctx, result, format)
+		if err != nil {
+			return "", err
+		}
+		over := handler.CountTokens(out) - maxTokens
+		if over <= 0 || allowance <= 0 {
+			return out, nil
+		}
+		allowance -= over
+	}
+}
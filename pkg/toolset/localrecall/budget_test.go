@@ -0,0 +1,201 @@
+package localrecall
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	lrclient "github.com/futuretea/localrecall-mcp-server/pkg/client"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
+)
+
+// decodeSearchResult parses a JSON search result rendered by fitSearchResult
+func decodeSearchResult(t *testing.T, out string) budgetedSearchResult {
+	t.Helper()
+	var result budgetedSearchResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	return result
+}
+
+// checkTruncated verifies that text is a prefix of original followed by the
+// truncation marker for the removed bytes
+func checkTruncated(t *testing.T, text, original string, removed int) {
+	t.Helper()
+	marker := fmt.Sprintf(truncationMarker, removed)
+	kept, ok := strings.CutSuffix(text, marker)
+	if !ok {
+		t.Fatalf("Expected text to end with %q, got %q", marker, text)
+	}
+	if !strings.HasPrefix(original, kept) {
+		t.Errorf("Expected the kept text to be a prefix of the original")
+	}
+	if len(kept)+removed != len(original) {
+		t.Errorf("Expected %d removed bytes, got %d", len(original)-len(kept), removed)
+	}
+	if !utf8.ValidString(kept) {
+		t.Errorf("Expected the kept text to be valid UTF-8")
+	}
+}
+
+func TestFitSearchResult_DropsLowestScoringHits(t *testing.T) {
+	content := strings.Repeat("word ", 80)
+	result := &lrclient.SearchResult{
+		Query: "q",
+		Results: []map[string]interface{}{
+			{"ID": "a", "Content": content, "Similarity": 0.9},
+			{"ID": "b", "Content": content, "Similarity": 0.5},
+			{"ID": "c", "Content": content, "Similarity": 0.7},
+		},
+		Count: 3,
+	}
+
+	out, err := fitSearchResult(context.Background(), result, "docs", "json", 250, false)
+	if err != nil {
+		t.Fatalf("fitSearchResult failed: %v", err)
+	}
+	if tokens := handler.CountTokens(out); tokens > 250 {
+		t.Errorf("Expected at most 250 tokens, got %d", tokens)
+	}
+
+	got := decodeSearchResult(t, out)
+	if len(got.Results) != 1 || got.Results[0]["ID"] != "a" || got.Count != 1 {
+		t.Fatalf("Expected only the best hit a to remain, got %v", got.Results)
+	}
+	if got.Budget == nil {
+		t.Fatal("Expected a budget notice")
+	}
+	if got.Budget.OmittedResults != 2 || !slices.Equal(got.Budget.OmittedIDs, []string{"b", "c"}) {
+		t.Errorf("Expected b then c to be omitted, got %v", got.Budget.OmittedIDs)
+	}
+	if got.Budget.TruncatedFields != 0 {
+		t.Errorf("Expected no truncated fields, got %d", got.Budget.TruncatedFields)
+	}
+}
+
+func TestFitSearchResult_HalvesLongestField(t *testing.T) {
+	content := strings.Repeat("日本語のテキスト。", 200)
+	result := &lrclient.SearchResult{
+		Query: "q",
+		Results: []map[string]interface{}{
+			{"ID": "a", "Content": content, "Context": "short context", "Similarity": 0.9},
+		},
+		Count: 1,
+	}
+
+	out, err := fitSearchResult(context.Background(), result, "docs", "json", 200, false)
+	if err != nil {
+		t.Fatalf("fitSearchResult failed: %v", err)
+	}
+	if tokens := handler.CountTokens(out); tokens > 200 {
+		t.Errorf("Expected at most 200 tokens, got %d", tokens)
+	}
+
+	got := decodeSearchResult(t, out)
+	if len(got.Results) != 1 || got.Budget == nil {
+		t.Fatalf("Expected the hit to remain with a budget notice, got %s", out)
+	}
+	if got.Budget.TruncatedFields != 1 {
+		t.Errorf("Expected 1 truncated field, got %d", got.Budget.TruncatedFields)
+	}
+	if got.Results[0]["Context"] != "short context" {
+		t.Errorf("Expected the shorter field to stay intact, got %q", got.Results[0]["Context"])
+	}
+	checkTruncated(t, got.Results[0]["Content"].(string), content, got.Budget.TruncatedBytes)
+}
+
+func TestFitSearchResult_WithinBudget(t *testing.T) {
+	result := &lrclient.SearchResult{
+		Query:   "q",
+		Results: []map[string]interface{}{{"ID": "a", "Content": "short", "Similarity": 0.9}},
+		Count:   1,
+	}
+
+	out, err := fitSearchResult(context.Background(), result, "docs", "json", 1000, false)
+	if err != nil {
+		t.Fatalf("fitSearchResult failed: %v", err)
+	}
+	if got := decodeSearchResult(t, out); got.Budget != nil || len(got.Results) != 1 {
+		t.Errorf("Expected the result unchanged, got %s", out)
+	}
+}
+
+func TestLongestField(t *testing.T) {
+	tests := []struct {
+		name   string
+		hits   []map[string]interface{}
+		want   fieldRef
+		wantOK bool
+	}{
+		{name: "no hits", hits: nil, wantOK: false},
+		{name: "no text", hits: []map[string]interface{}{{"ID": "a", "Content": ""}}, wantOK: false},
+		{
+			name:   "longest content",
+			hits:   []map[string]interface{}{{"Content": "abc"}, {"Content": "abcdef"}},
+			want:   fieldRef{index: 1, key: "Content"},
+			wantOK: true,
+		},
+		{
+			name:   "longest context",
+			hits:   []map[string]interface{}{{"Content": "abc", "Context": "abcdef"}},
+			want:   fieldRef{index: 0, key: "Context"},
+			wantOK: true,
+		},
+		{
+			name:   "first on ties",
+			hits:   []map[string]interface{}{{"Content": "abc"}, {"Content": "xyz"}},
+			want:   fieldRef{index: 0, key: "Content"},
+			wantOK: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := longestField(tt.hits)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("Expected (%v, %v), got (%v, %v)", tt.want, tt.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestFitEntryContent(t *testing.T) {
+	content := strings.Repeat("Grüße aus Köln! ", 300)
+	entry := &lrclient.EntryContent{Collection: "docs", Entry: "a.md", Content: content, ChunkCount: 3}
+
+	out, err := fitEntryContent(context.Background(), entry, "json", 100)
+	if err != nil {
+		t.Fatalf("fitEntryContent failed: %v", err)
+	}
+	if tokens := handler.CountTokens(out); tokens > 100 {
+		t.Errorf("Expected at most 100 tokens, got %d", tokens)
+	}
+	if entry.Content != content {
+		t.Error("Expected the entry to be left unchanged")
+	}
+
+	var got budgetedEntryContent
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if got.Budget == nil || got.Budget.TruncatedFields != 1 {
+		t.Fatalf("Expected a budget notice for one truncated field, got %s", out)
+	}
+	checkTruncated(t, got.Content, content, got.Budget.TruncatedBytes)
+}
+
+func TestFitEntryContent_WithinBudget(t *testing.T) {
+	entry := &lrclient.EntryContent{Collection: "docs", Entry: "a.md", Content: "short"}
+
+	out, err := fitEntryContent(context.Background(), entry, "json", 1000)
+	if err != nil {
+		t.Fatalf("fitEntryContent failed: %v", err)
+	}
+	if strings.Contains(out, "budget") || !strings.Contains(out, `"short"`) {
+		t.Errorf("Expected the entry unchanged, got %s", out)
+	}
+}
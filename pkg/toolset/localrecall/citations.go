@@ -0,0 +1,187 @@
+package localrecall
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	lrclient "github.com/futuretea/localrecall-mcp-server/pkg/client"
+)
+
+const (
+	// maxSnippetChars limits the length of each rendered snippet
+	maxSnippetChars = 500
+	// minHighlightTermLen skips short query words such as "a" or "of"
+	minHighlightTermLen = 3
+	// unknownSource labels hits whose metadata carries no source entry
+	unknownSource = "(unknown source)"
+)
+
+// highlightStopWords are common query words that are not worth highlighting
+var highlightStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true,
+	"this": true, "from": true, "what": true, "how": true, "are": true,
+}
+
+// citedResults groups search hits by source entry for citation-friendly output
+type citedResults struct {
+	Query      string        `json:"query"`
+	Collection string        `json:"collection,omitempty"`
+	Sources    []citedSource `json:"sources"`
+	Count      int           `json:"count"`
+	Budget     *budgetNotice `json:"budget,omitempty"`
+
+	highlight *regexp.Regexp
+}
+
+// citedSource is a single numbered citation
+type citedSource struct {
+	Citation  int          `json:"citation"`
+	Entry     string       `json:"entry"`
+	BestScore float64      `json:"best_score"`
+	Snippets  []citedChunk `json:"snippets"`
+}
+
+// citedChunk is a matched chunk within a cited source
+type citedChunk struct {
+	ID    string  `json:"id,omitempty"`
+	Score float64 `json:"score"`
+	Text  string  `json:"text"`
+}
+
+// newCitedResults groups hits by source in order of first appearance, so the
+// highest-ranked source receives citation [1].
+func newCitedResults(collection string, result *lrclient.SearchResult) *citedResults {
+	cited := &citedResults{
+		Query:      result.Query,
+		Collection: collection,
+		Sources:    []citedSource{},
+		highlight:  highlightPattern(result.Query),
+	}
+
+	index := make(map[string]int)
+	for _, hit := range result.Results {
+		source := hitSource(hit)
+		if source == "" {
+			source = unknownSource
+		}
+		i, ok := index[source]
+		if !ok {
+			i = len(cited.Sources)
+			index[source] = i
+			cited.Sources = append(cited.Sources, citedSource{
+				Citation: i + 1,
+				Entry:    source,
+			})
+		}
+
+		// Expanded context is already budgeted, so only bare chunks are shortened
+		text, ok := hit["Context"].(string)
+		if !ok || text == "" {
+			text = shortSnippet(hitContent(hit))
+		}
+		id, _ := hit["ID"].(string)
+		score := hitSimilarity(hit)
+
+		src := &cited.Sources[i]
+		if score > src.BestScore {
+			src.BestScore = score
+		}
+		src.Snippets = append(src.Snippets, citedChunk{
+			ID:    id,
+			Score: score,
+			Text:  text,
+		})
+		cited.Count++
+	}
+	return cited
+}
+
+// Markdown renders the grouped results with numbered citations and
+// highlighted query terms.
+func (c *citedResults) Markdown() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "## Search results for %q\n\n", c.Query)
+	if len(c.Sources) == 0 {
+		b.WriteString("No matching entries found.\n")
+		return b.String()
+	}
+
+	for _, src := range c.Sources {
+		fmt.Fprintf(&b, "### [%d] %s (score: %.3f)\n\n", src.Citation, src.Entry, src.BestScore)
+		for _, chunk := range src.Snippets {
+			b.WriteString(quote(c.highlightTerms(chunk.Text)))
+			fmt.Fprintf(&b, ">\n> — score %.3f\n\n", chunk.Score)
+		}
+	}
+
+	writeSourceList(&b, c.Collection, c.Sources)
+
+	if c.Budget != nil {
+		fmt.Fprintf(&b, "\n_%s", c.Budget.Hint)
+		if c.Budget.OmittedResults > 0 {
+			fmt.Fprintf(&b, " Omitted %d lower-scoring result(s).", c.Budget.OmittedResults)
+		}
+		if c.Budget.TruncatedFields > 0 {
+			fmt.Fprintf(&b, " Truncated %d snippet(s).", c.Budget.TruncatedFields)
+		}
+		b.WriteString("_\n")
+	}
+	return b.String()
+}
+
+// writeSourceList renders the numbered list of cited entries
+func writeSourceList(b *strings.Builder, collection string, sources []citedSource) {
+	b.WriteString("### Sources\n\n")
+	for _, src := range sources {
+		if collection != "" {
+			fmt.Fprintf(b, "[%d]: %s/%s\n", src.Citation, collection, src.Entry)
+		} else {
+			fmt.Fprintf(b, "[%d]: %s\n", src.Citation, src.Entry)
+		}
+	}
+}
+
+// shortSnippet collapses whitespace and shortens a chunk for display
+func shortSnippet(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) > maxSnippetChars {
+		text = truncateUTF8(text, maxSnippetChars) + "…"
+	}
+	return text
+}
+
+// highlightTerms wraps query terms in the text in bold
+func (c *citedResults) highlightTerms(text string) string {
+	if c.highlight == nil {
+		return text
+	}
+	return c.highlight.ReplaceAllString(text, "**$1**")
+}
+
+// quote renders text as a Markdown blockquote
+func quote(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = "> " + line
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// highlightPattern builds a case-insensitive whole-word pattern matching the
+// query terms, or nil if the query has no terms worth highlighting.
+func highlightPattern(query string) *regexp.Regexp {
+	var terms []string
+	for term := range tokenSet(query) {
+		if len(term) >= minHighlightTermLen && !highlightStopWords[term] {
+			terms = append(terms, regexp.QuoteMeta(term))
+		}
+	}
+	if len(terms) == 0 {
+		return nil
+	}
+	sort.Strings(terms)
+	return regexp.MustCompile(`(?i)\b(` + strings.Join(terms, "|") + `)\b`)
+}
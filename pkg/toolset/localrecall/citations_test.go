@@ -0,0 +1,109 @@
+package localrecall
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/futuretea/localrecall-mcp-server/internal/lrtest"
+	lrclient "github.com/futuretea/localrecall-mcp-server/pkg/client"
+)
+
+func TestNewCitedResults(t *testing.T) {
+	noSource := lrtest.Hit("x#1", "orphan chunk", 0.5, "")
+	delete(noSource, "Metadata")
+	expanded := lrtest.Hit("a#2", "short chunk", 0.9, "a.md")
+	expanded["Context"] = "the expanded context"
+
+	cited := newCitedResults("docs", &lrclient.SearchResult{
+		Query: "chunk",
+		Results: []map[string]interface{}{
+			lrtest.Hit("b#1", "first  chunk\nof b", 0.95, "b.md"),
+			lrtest.Hit("a#1", "first chunk of a", 0.7, "a.md"),
+			expanded,
+			noSource,
+		},
+	})
+
+	if cited.Count != 4 || len(cited.Sources) != 3 {
+		t.Fatalf("Expected 4 hits from 3 sources, got %+v", cited)
+	}
+	b, a, unknown := cited.Sources[0], cited.Sources[1], cited.Sources[2]
+	if b.Citation != 1 || b.Entry != "b.md" || a.Citation != 2 || a.Entry != "a.md" || unknown.Entry != unknownSource {
+		t.Errorf("Expected citations in order of first appearance, got %+v", cited.Sources)
+	}
+	if a.BestScore != 0.9 || len(a.Snippets) != 2 {
+		t.Errorf("Expected a.md to group both hits with the best score, got %+v", a)
+	}
+	if b.Snippets[0].Text != "first chunk of b" {
+		t.Errorf("Expected whitespace in chunks to be collapsed, got %q", b.Snippets[0].Text)
+	}
+	if a.Snippets[1].Text != "the expanded context" {
+		t.Errorf("Expected expanded context to be cited, got %q", a.Snippets[1].Text)
+	}
+}
+
+func TestShortSnippet(t *testing.T) {
+	long := strings.Repeat("x", maxSnippetChars+10)
+	if got := shortSnippet(long); got != strings.Repeat("x", maxSnippetChars)+"…" {
+		t.Errorf("Expected a long chunk to be shortened, got %d bytes", len(got))
+	}
+}
+
+func TestCitedResults_Markdown(t *testing.T) {
+	cited := newCitedResults("docs", &lrclient.SearchResult{
+		Query: "how does the Cache expire",
+		Results: []map[string]interface{}{
+			lrtest.Hit("a#1", "The cache entries expire.\nCaches are cleared hourly.", 0.8, "a.md"),
+		},
+	})
+	got := cited.Markdown()
+	for _, want := range []string{
+		"## Search results for \"how does the Cache expire\"\n",
+		"### [1] a.md (score: 0.800)\n",
+		"> The **cache** entries **expire**. Caches are cleared hourly.\n>\n> — score 0.800\n",
+		"### Sources\n\n[1]: docs/a.md\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected Markdown to contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "**the**") || strings.Contains(got, "**how**") {
+		t.Errorf("Expected stop words not to be highlighted, got:\n%s", got)
+	}
+
+	empty := newCitedResults("", &lrclient.SearchResult{Query: "nothing"})
+	if got := empty.Markdown(); !strings.Contains(got, "No matching entries found.") {
+		t.Errorf("Expected an empty result notice, got:\n%s", got)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	if got := quote("first\nsecond\n"); got != "> first\n> second\n" {
+		t.Errorf("Unexpected blockquote %q", got)
+	}
+}
+
+func TestHighlightPattern(t *testing.T) {
+	if p := highlightPattern("how to do it"); p != nil {
+		t.Errorf("Expected no pattern for stop and short words, got %s", p)
+	}
+	p := highlightPattern("a+b (cache)")
+	if p == nil || !p.MatchString("CACHE") || p.MatchString("caches") {
+		t.Errorf("Expected a case-insensitive whole-word pattern, got %v", p)
+	}
+}
+
+func TestSearchHandler_Markdown(t *testing.T) {
+	server, client := newTestClient(t)
+	server.AddEntry("docs", "a.md", "rotate the signing keys")
+
+	out, err := callTool(client, SearchHandler, map[string]interface{}{
+		"query": "signing", "collection_name": "docs", "format": "markdown",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "### [1] a.md") || !strings.Contains(out, "[1]: docs/a.md") {
+		t.Errorf("Expected cited Markdown output, got:\n%s", out)
+	}
+}
@@ -0,0 +1,257 @@
+package localrecall
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/core/logging"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
+)
+
+// ProgressFunc reports that done of total entries have been processed
+type ProgressFunc func(done, total int, entry string)
+
+// CloneOptions controls CloneCollection
+type CloneOptions struct {
+	// IncludeSources registers the source collection's external sources on the
+	// target. Renames should always include them, as the source loses them.
+	IncludeSources bool
+	// Rename resets the source collection once the clone has been verified
+	Rename bool
+	// DryRun only reports the entries and sources that would be copied
+	DryRun bool
+	// Progress is called after each entry is copied (optional)
+	Progress ProgressFunc
+}
+
+// CloneResult is the response of clone_collection and rename_collection. A
+// dry run lists the entries and sources that would be copied in Entries and
+// Sources. Cancelled is set when the call was cancelled or timed out while entries were
+// copied; Copied then lists the entries already in the target, and the source
+// is left unchanged.
+type CloneResult struct {
+	Operation     string       `json:"operation" yaml:"operation"`
+	Source        string       `json:"source" yaml:"source"`
+	Target        string       `json:"target" yaml:"target"`
+	EntriesTotal  int          `json:"entries_total" yaml:"entries_total"`
+	EntriesCopied int          `json:"entries_copied" yaml:"entries_copied"`
+	SourcesCopied []string     `json:"sources_copied" yaml:"sources_copied"`
+	Verified      bool         `json:"verified" yaml:"verified"`
+	SourceReset   bool         `json:"source_reset,omitempty" yaml:"source_reset,omitempty"`
+	DryRun        bool         `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+	Entries       []string     `json:"entries,omitempty" yaml:"entries,omitempty"`
+	Sources       []string     `json:"sources,omitempty" yaml:"sources,omitempty"`
+	Cancelled     bool         `json:"cancelled,omitempty" yaml:"cancelled,omitempty"`
+	Copied        []string     `json:"copied,omitempty" yaml:"copied,omitempty"`
+	Errors        []entryError `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// CloneCollection creates target and copies every entry and, optionally,
+// every external source of source into it. With opts.Rename the source is
+// reset after the copy has been verified against it. If ctx is cancelled while
+// entries are copied, the copy stops and the partial result is returned with
+// Cancelled set; once the source is being reset, the reset is completed.
+func CloneCollection(ctx context.Context, client *toolset.LocalRecallClient, source, target string, opts CloneOptions) (*CloneResult, error) {
+	result := &CloneResult{
+		Operation:     "clone",
+		Source:        source,
+		Target:        target,
+		SourcesCopied: []string{},
+	}
+	if opts.Rename {
+		result.Operation = "rename"
+	}
+	if source == target {
+		return nil, fmt.Errorf("source and target collection are the same")
+	}
+
+	collections, err := client.Client.ListCollections(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list collections failed: %w", err)
+	}
+	if !slices.Contains(collections.Collections, source) {
+		return nil, fmt.Errorf("collection %s does not exist", source)
+	}
+	if slices.Contains(collections.Collections, target) {
+		return nil, fmt.Errorf("collection %s already exists", target)
+	}
+
+	files, err := client.Client.ListFiles(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("list files of %s failed: %w", source, err)
+	}
+	sources, err := client.Client.ListSources(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("list sources of %s failed: %w", source, err)
+	}
+
+	if opts.DryRun {
+		result.DryRun = true
+		result.EntriesTotal = len(files.Entries)
+		result.Entries = files.Entries
+		if opts.IncludeSources {
+			for _, src := range sources.Sources {
+				if url, _ := src["url"].(string); url != "" {
+					result.Sources = append(result.Sources, url)
+				}
+			}
+		}
+		return result, nil
+	}
+
+	if _, err := client.Client.CreateCollection(ctx, target); err != nil {
+		return nil, fmt.Errorf("create collection %s failed: %w", target, err)
+	}
+	client.InvalidateCollection(target)
+
+	result.EntriesTotal = len(files.Entries)
+	copied := make([]string, 0, len(files.Entries))
+	for i, entry := range files.Entries {
+		if ctx.Err() != nil {
+			break
+		}
+		_, err := transferEntry(ctx, client, transferRequest{
+			operation:        opCopy,
+			sourceCollection: source,
+			sourceEntry:      entry,
+			targetCollection: target,
+			targetEntry:      entry,
+		}, false)
+		if err != nil {
+			result.Errors = append(result.Errors, entryError{Entry: entry, Error: err.Error()})
+		} else {
+			copied = append(copied, entry)
+			result.EntriesCopied++
+		}
+		if opts.Progress != nil {
+			opts.Progress(i+1, len(files.Entries), entry)
+		}
+	}
+	if ctx.Err() != nil {
+		// Report what reached the target so the caller can resume or clean up
+		result.Cancelled = true
+		result.Copied = copied
+		return result, nil
+	}
+
+	// Sources are registered after the entries so fetched content does not
+	// collide with entries being copied
+	if opts.IncludeSources {
+		for _, src := range sources.Sources {
+			url, _ := src["url"].(string)
+			if url == "" {
+				continue
+			}
+			interval := handler.GetIntParam(src, "update_interval", 0)
+			if _, err := client.Client.RegisterSource(ctx, target, url, interval); err != nil {
+				result.Errors = append(result.Errors, entryError{Entry: url, Error: fmt.Sprintf("register source failed: %v", err)})
+				continue
+			}
+			result.SourcesCopied = append(result.SourcesCopied, url)
+		}
+		client.InvalidateComputed(target)
+	}
+
+	// Entries fetched by newly registered sources may appear in the target,
+	// so only missing or differing content fails verification
+	comparison, err := compareCollections(ctx, client, source, target)
+	if err != nil {
+		return result, fmt.Errorf("verification failed: %w", err)
+	}
+	result.Verified = len(comparison.OnlyInA) == 0 && len(comparison.Differing) == 0 && len(comparison.Errors) == 0 &&
+		(!opts.IncludeSources || len(comparison.Sources.OnlyInA) == 0)
+
+	if !opts.Rename {
+		return result, nil
+	}
+	if !result.Verified || len(result.Errors) > 0 {
+		return result, fmt.Errorf("rename aborted: the copy in %s could not be verified (%d missing, %d differing, %d errors); %s was left unchanged",
+			target, len(comparison.OnlyInA), len(comparison.Differing), len(result.Errors), source)
+	}
+
+	// A cancelled reset would leave the source half emptied
+	if err := resetRenamedSource(context.WithoutCancel(ctx), client, source, target, files.Entries, sources.Sources); err != nil {
+		return result, err
+	}
+	result.SourceReset = true
+	return result, nil
+}
+
+// resetRenamedSource empties the source of a verified rename, carrying
+// expiry schedules over to the target
+func resetRenamedSource(ctx context.Context, client *toolset.LocalRecallClient, source, target string, entries []string, sources []map[string]interface{}) error {
+	// Remove sources first so the reset collection is not repopulated
+	for _, src := range sources {
+		if url, _ := src["url"].(string); url != "" {
+			if err := client.Client.RemoveSource(ctx, source, url); err != nil {
+				return fmt.Errorf("renamed to %s, but removing source %s from %s failed: %w", target, url, source, err)
+			}
+			client.InvalidateComputed(source)
+		}
+	}
+
+	for _, entry := range entries {
+		if record, ok := client.Expiry.Get(source, entry); ok {
+			if err := client.Expiry.Set(target, entry, record.ExpiresAt); err != nil {
+				logging.Warn("Failed to move expiry of %s/%s to %s: %v", source, entry, target, err)
+			}
+		}
+	}
+
+	if _, err := client.Client.ResetCollection(ctx, source); err != nil {
+		return fmt.Errorf("renamed to %s, but resetting %s failed: %w", target, source, err)
+	}
+	client.InvalidateCollection(source)
+	if err := client.Expiry.RemoveCollection(source); err != nil {
+		logging.Warn("Failed to remove expiries of collection %s: %v", source, err)
+	}
+	return nil
+}
+
+// CloneCollectionHandler handles clone_collection requests
+func CloneCollectionHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
+	return cloneCollectionHandler(ctx, clientInterface, params, false)
+}
+
+// RenameCollectionHandler handles rename_collection requests
+func RenameCollectionHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
+	return cloneCollectionHandler(ctx, clientInterface, params, true)
+}
+
+// cloneCollectionHandler clones or renames a collection, reporting progress
+// per copied entry
+func cloneCollectionHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}, rename bool) (string, error) {
+	client, err := getClient(clientInterface)
+	if err != nil {
+		return "", err
+	}
+
+	source, err := handler.RequireStringParam(params, "source_collection")
+	if err != nil {
+		return "", err
+	}
+	target, err := handler.RequireStringParam(params, "target_collection")
+	if err != nil {
+		return "", err
+	}
+
+	opts := CloneOptions{
+		IncludeSources: rename || handler.GetBoolParam(params, "include_sources", true),
+		Rename:         rename,
+		DryRun:         handler.GetBoolParam(params, "dry_run", false),
+		Progress: func(done, total int, entry string) {
+			logging.Debug("Copying %s to %s: %d/%d (%s)", source, target, done, total, entry)
+			handler.ReportProgress(ctx, done, total, fmt.Sprintf("Copied %s to %s", entry, target))
+		},
+	}
+	format := handler.GetStringParam(params, "format", "json")
+
+	result, err := CloneCollection(ctx, client, source, target, opts)
+	if err != nil {
+		return "", err
+	}
+
+	return handler.FormatResult(ctx, result, format)
+}
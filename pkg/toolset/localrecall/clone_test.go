@@ -0,0 +1,201 @@
+package localrecall
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
+)
+
+func TestCloneCollection(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources bool
+	}{
+		{name: "with sources", sources: true},
+		{name: "without sources", sources: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := newTestClient(t)
+			server.AddEntry("prod", "a.md", "a")
+			server.AddEntry("prod", "b.md", "b")
+			server.AddSource("prod", "https://example.com/feed", 60)
+
+			result, err := CloneCollection(context.Background(), client, "prod", "fork", CloneOptions{IncludeSources: tt.sources})
+			if err != nil {
+				t.Fatalf("Clone failed: %v", err)
+			}
+			if !result.Verified || result.EntriesCopied != 2 || result.EntriesTotal != 2 || result.SourceReset {
+				t.Errorf("Unexpected result %+v", result)
+			}
+			if got := server.Entries("fork"); !slices.Equal(got, []string{"a.md", "b.md"}) {
+				t.Errorf("Expected both entries in the clone, got %v", got)
+			}
+			if got := server.Entries("prod"); len(got) != 2 {
+				t.Errorf("Expected the source to keep its entries, got %v", got)
+			}
+			if got := len(server.Sources("fork")); got != map[bool]int{true: 1, false: 0}[tt.sources] {
+				t.Errorf("Expected the sources to be copied only with IncludeSources, got %d", got)
+			}
+		})
+	}
+}
+
+func TestCloneCollection_Conflicts(t *testing.T) {
+	tests := []struct {
+		name           string
+		source, target string
+		want           string
+	}{
+		{name: "same collection", source: "prod", target: "prod", want: "are the same"},
+		{name: "missing source", source: "missing", target: "fork", want: "collection missing does not exist"},
+		{name: "existing target", source: "prod", target: "staging", want: "collection staging already exists"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := newTestClient(t)
+			server.AddEntry("prod", "a.md", "a")
+			server.AddEntry("staging", "old.md", "old")
+
+			for _, rename := range []bool{false, true} {
+				_, err := CloneCollection(context.Background(), client, tt.source, tt.target, CloneOptions{Rename: rename})
+				if err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+				}
+			}
+			if got := server.Entries("staging"); !slices.Equal(got, []string{"old.md"}) {
+				t.Errorf("Expected the existing target to be left alone, got %v", got)
+			}
+			if got := server.Entries("prod"); !slices.Equal(got, []string{"a.md"}) {
+				t.Errorf("Expected the source to be left alone, got %v", got)
+			}
+		})
+	}
+}
+
+func TestRenameCollection(t *testing.T) {
+	server, client := newTestClient(t)
+	server.AddEntry("old", "a.md", "a")
+	server.AddEntry("old", "b.md", "b")
+	server.AddSource("old", "https://example.com/feed", 60)
+
+	result, err := CloneCollection(context.Background(), client, "old", "new", CloneOptions{IncludeSources: true, Rename: true})
+	if err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if result.Operation != "rename" || !result.Verified || !result.SourceReset {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if got := server.Entries("new"); !slices.Equal(got, []string{"a.md", "b.md"}) {
+		t.Errorf("Expected the entries under the new name, got %v", got)
+	}
+	if got := server.Sources("new"); len(got) != 1 {
+		t.Errorf("Expected the source under the new name, got %v", got)
+	}
+	if got := server.Entries("old"); len(got) != 0 {
+		t.Errorf("Expected the old collection to be reset, got %v", got)
+	}
+	if got := server.Sources("old"); len(got) != 0 {
+		t.Errorf("Expected the old collection's sources to be removed, got %v", got)
+	}
+}
+
+func TestRenameCollection_AbortsUnverifiedCopy(t *testing.T) {
+	server, client := newTestClient(t)
+	server.AddEntry("old", "a.md", "a")
+	server.AddEntry("old", "b.md", "b")
+	server.FailUpload = func(collection, entry string) bool { return entry == "b.md" }
+
+	result, err := CloneCollection(context.Background(), client, "old", "new", CloneOptions{IncludeSources: true, Rename: true})
+	if err == nil || !strings.Contains(err.Error(), "rename aborted") {
+		t.Fatalf("Expected the rename to be aborted, got %v", err)
+	}
+	if result.Verified || result.SourceReset || len(result.Errors) != 1 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if got := server.Entries("old"); !slices.Equal(got, []string{"a.md", "b.md"}) {
+		t.Errorf("Expected the old collection to be left unchanged, got %v", got)
+	}
+}
+
+func TestCloneCollection_DryRun(t *testing.T) {
+	server, client := newTestClient(t)
+	server.AddEntry("old", "a.md", "a")
+	server.AddEntry("old", "b.md", "b")
+	server.AddSource("old", "https://example.com/feed", 60)
+
+	for _, h := range []toolHandler{CloneCollectionHandler, RenameCollectionHandler} {
+		result := callToolJSON[CloneResult](t, client, h, map[string]interface{}{
+			"source_collection": "old",
+			"target_collection": "new",
+			"dry_run":           true,
+		})
+		if !result.DryRun || result.EntriesTotal != 2 || result.EntriesCopied != 0 || result.SourceReset {
+			t.Errorf("Unexpected %s result %+v", result.Operation, result)
+		}
+		if !slices.Equal(result.Entries, []string{"a.md", "b.md"}) || !slices.Equal(result.Sources, []string{"https://example.com/feed"}) {
+			t.Errorf("Expected the entries and sources to copy, got %v and %v", result.Entries, result.Sources)
+		}
+	}
+	if server.HasCollection("new") {
+		t.Error("Expected a dry run not to create the target")
+	}
+	if got := server.Entries("old"); len(got) != 2 || len(server.Sources("old")) != 1 {
+		t.Errorf("Expected a dry run to leave the source unchanged, got %v", got)
+	}
+}
+
+func TestCloneCollection_Cancelled(t *testing.T) {
+	for _, rename := range []bool{false, true} {
+		server, client := newTestClient(t)
+		server.AddEntry("old", "a.md", "a")
+		server.AddEntry("old", "b.md", "b")
+		server.AddEntry("old", "c.md", "c")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		result, err := CloneCollection(ctx, client, "old", "new", CloneOptions{
+			IncludeSources: true,
+			Rename:         rename,
+			// Cancel once the first entry has been copied
+			Progress: func(done, total int, entry string) { cancel() },
+		})
+		if err != nil {
+			t.Fatalf("Expected the partial result, got %v", err)
+		}
+		if !result.Cancelled || result.EntriesCopied != 1 || !slices.Equal(result.Copied, []string{"a.md"}) || result.SourceReset {
+			t.Errorf("Unexpected result %+v", result)
+		}
+		if got := server.Entries("new"); !slices.Equal(got, []string{"a.md"}) {
+			t.Errorf("Expected the target to hold the copied entry, got %v", got)
+		}
+		if got := server.Entries("old"); len(got) != 3 {
+			t.Errorf("Expected the source to be left unchanged, got %v", got)
+		}
+	}
+}
+
+func TestCloneCollectionHandler_ReportsProgress(t *testing.T) {
+	server, client := newTestClient(t)
+	server.AddEntry("old", "a.md", "a")
+	server.AddEntry("old", "b.md", "b")
+
+	var reports []string
+	ctx := handler.WithProgressReporter(context.Background(), func(done, total int, message string) {
+		reports = append(reports, fmt.Sprintf("%d/%d %s", done, total, message))
+	})
+	if _, err := CloneCollectionHandler(ctx, client, map[string]interface{}{
+		"source_collection": "old",
+		"target_collection": "new",
+	}); err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	want := []string{"1/2 Copied a.md to new", "2/2 Copied b.md to new"}
+	if !slices.Equal(reports, want) {
+		t.Errorf("Expected progress %v, got %v", want, reports)
+	}
+}
@@ -0,0 +1,266 @@
+package localrecall
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
+)
+
+// collectionComparison is the response of the compare_collections tool
+type collectionComparison struct {
+	CollectionA string            `json:"collection_a" yaml:"collection_a"`
+	CollectionB string            `json:"collection_b" yaml:"collection_b"`
+	Match       bool              `json:"match" yaml:"match"`
+	OnlyInA     []string          `json:"only_in_a" yaml:"only_in_a"`
+	OnlyInB     []string          `json:"only_in_b" yaml:"only_in_b"`
+	Differing   []entryDifference `json:"differing" yaml:"differing"`
+	Identical   int               `json:"identical" yaml:"identical"`
+	Sources     sourceComparison  `json:"sources" yaml:"sources"`
+	Errors      []entryError      `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// entryDifference describes an entry whose content differs between collections
+type entryDifference struct {
+	Entry  string `json:"entry" yaml:"entry"`
+	HashA  string `json:"sha256_a" yaml:"sha256_a"`
+	HashB  string `json:"sha256_b" yaml:"sha256_b"`
+	BytesA int    `json:"bytes_a" yaml:"bytes_a"`
+	BytesB int    `json:"bytes_b" yaml:"bytes_b"`
+}
+
+// sourceComparison describes differences between registered external sources
+type sourceComparison struct {
+	OnlyInA   []string           `json:"only_in_a" yaml:"only_in_a"`
+	OnlyInB   []string           `json:"only_in_b" yaml:"only_in_b"`
+	Differing []sourceDifference `json:"differing" yaml:"differing"`
+}
+
+// sourceDifference describes a source registered in both collections with different settings
+type sourceDifference struct {
+	URL             string `json:"url" yaml:"url"`
+	UpdateIntervalA string `json:"update_interval_a" yaml:"update_interval_a"`
+	UpdateIntervalB string `json:"update_interval_b" yaml:"update_interval_b"`
+}
+
+// Markdown renders the comparison as a short report
+func (c *collectionComparison) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Comparison of %s (A) and %s (B)\n\n", c.CollectionA, c.CollectionB)
+	if c.Match {
+		fmt.Fprintf(&b, "The collections match: %d identical entries.\n", c.Identical)
+	} else {
+		fmt.Fprintf(&b, "%d identical, %d differing, %d only in A, %d only in B.\n",
+			c.Identical, len(c.Differing), len(c.OnlyInA), len(c.OnlyInB))
+	}
+
+	writeList := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", title)
+		for _, item := range items {
+			fmt.Fprintf(&b, "- %s\n", item)
+		}
+	}
+	writeList("Only in A", c.OnlyInA)
+	writeList("Only in B", c.OnlyInB)
+	if len(c.Differing) > 0 {
+		b.WriteString("\n### Differing content\n\n")
+		for _, d := range c.Differing {
+			fmt.Fprintf(&b, "- %s (%d → %d bytes)\n", d.Entry, d.BytesA, d.BytesB)
+		}
+	}
+	writeList("Sources only in A", c.Sources.OnlyInA)
+	writeList("Sources only in B", c.Sources.OnlyInB)
+	if len(c.Sources.Differing) > 0 {
+		b.WriteString("\n### Sources with different settings\n\n")
+		for _, d := range c.Sources.Differing {
+			fmt.Fprintf(&b, "- %s (update interval %s → %s)\n", d.URL, d.UpdateIntervalA, d.UpdateIntervalB)
+		}
+	}
+	if len(c.Errors) > 0 {
+		b.WriteString("\n### Errors\n\n")
+		for _, e := range c.Errors {
+			fmt.Fprintf(&b, "- %s: %s\n", e.Entry, e.Error)
+		}
+	}
+	return b.String()
+}
+
+// contentHash returns the hex SHA-256 of content
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// CompareCollectionsHandler compares the entries and sources of two collections
+func CompareCollectionsHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
+	client, err := getClient(clientInterface)
+	if err != nil {
+		return "", err
+	}
+
+	collectionA, err := handler.RequireStringParam(params, "collection_a")
+	if err != nil {
+		return "", err
+	}
+	collectionB, err := handler.RequireStringParam(params, "collection_b")
+	if err != nil {
+		return "", err
+	}
+
+	format := handler.GetStringParam(params, "format", "json")
+
+	result, err := compareCollections(ctx, client, collectionA, collectionB)
+	if err != nil {
+		return "", err
+	}
+
+	return handler.FormatResult(ctx, result, format)
+}
+
+// compareCollections compares two collections. Entries present in both are
+// compared by content hash.
+func compareCollections(ctx context.Context, client *toolset.LocalRecallClient, collectionA, collectionB string) (*collectionComparison, error) {
+	filesA, err := client.Client.ListFiles(ctx, collectionA)
+	if err != nil {
+		return nil, fmt.Errorf("list files of %s failed: %w", collectionA, err)
+	}
+	filesB, err := client.Client.ListFiles(ctx, collectionB)
+	if err != nil {
+		return nil, fmt.Errorf("list files of %s failed: %w", collectionB, err)
+	}
+
+	result := &collectionComparison{
+		CollectionA: collectionA,
+		CollectionB: collectionB,
+		Differing:   []entryDifference{},
+	}
+
+	var common []string
+	result.OnlyInA, result.OnlyInB, common = splitSets(filesA.Entries, filesB.Entries)
+
+	fetchedA := fetchEntries(ctx, client, collectionA, common)
+	fetchedB := fetchEntries(ctx, client, collectionB, common)
+	for i, entry := range common {
+		a, b := fetchedA[i], fetchedB[i]
+		if a.Err != nil {
+			result.Errors = append(result.Errors, entryError{Entry: collectionA + "/" + entry, Error: a.Err.Error()})
+			continue
+		}
+		if b.Err != nil {
+			result.Errors = append(result.Errors, entryError{Entry: collectionB + "/" + entry, Error: b.Err.Error()})
+			continue
+		}
+		hashA, hashB := contentHash(a.Content.Content), contentHash(b.Content.Content)
+		if hashA == hashB {
+			result.Identical++
+			continue
+		}
+		result.Differing = append(result.Differing, entryDifference{
+			Entry:  entry,
+			HashA:  hashA,
+			HashB:  hashB,
+			BytesA: len(a.Content.Content),
+			BytesB: len(b.Content.Content),
+		})
+	}
+
+	if err := compareSources(ctx, client, result); err != nil {
+		return nil, err
+	}
+
+	result.Match = len(result.OnlyInA) == 0 && len(result.OnlyInB) == 0 && len(result.Differing) == 0 &&
+		len(result.Sources.OnlyInA) == 0 && len(result.Sources.OnlyInB) == 0 && len(result.Sources.Differing) == 0 &&
+		len(result.Errors) == 0
+	return result, nil
+}
+
+// compareSources fills in the source list differences of a comparison
+func compareSources(ctx context.Context, client *toolset.LocalRecallClient, result *collectionComparison) error {
+	sourcesA, err := client.Client.ListSources(ctx, result.CollectionA)
+	if err != nil {
+		return fmt.Errorf("list sources of %s failed: %w", result.CollectionA, err)
+	}
+	sourcesB, err := client.Client.ListSources(ctx, result.CollectionB)
+	if err != nil {
+		return fmt.Errorf("list sources of %s failed: %w", result.CollectionB, err)
+	}
+
+	intervalsA, intervalsB := sourceIntervals(sourcesA.Sources), sourceIntervals(sourcesB.Sources)
+	var common []string
+	result.Sources.OnlyInA, result.Sources.OnlyInB, common = splitSets(mapKeys(intervalsA), mapKeys(intervalsB))
+	result.Sources.Differing = []sourceDifference{}
+	for _, url := range common {
+		if intervalsA[url] != intervalsB[url] {
+			result.Sources.Differing = append(result.Sources.Differing, sourceDifference{
+				URL:             url,
+				UpdateIntervalA: intervalsA[url],
+				UpdateIntervalB: intervalsB[url],
+			})
+		}
+	}
+	return nil
+}
+
+// sourceIntervals maps source URLs to their update interval
+func sourceIntervals(sources []map[string]interface{}) map[string]string {
+	intervals := make(map[string]string, len(sources))
+	for _, src := range sources {
+		url, _ := src["url"].(string)
+		if url == "" {
+			continue
+		}
+		interval := ""
+		if v, ok := src["update_interval"]; ok && v != nil {
+			interval = fmt.Sprint(v)
+		}
+		intervals[url] = interval
+	}
+	return intervals
+}
+
+// mapKeys returns the keys of m
+func mapKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// splitSets returns the sorted items only in a, only in b, and in both
+func splitSets(a, b []string) (onlyA, onlyB, both []string) {
+	inA := make(map[string]bool, len(a))
+	for _, item := range a {
+		inA[item] = true
+	}
+	inB := make(map[string]bool, len(b))
+	for _, item := range b {
+		inB[item] = true
+	}
+
+	onlyA, onlyB, both = []string{}, []string{}, []string{}
+	for item := range inA {
+		if inB[item] {
+			both = append(both, item)
+		} else {
+			onlyA = append(onlyA, item)
+		}
+	}
+	for item := range inB {
+		if !inA[item] {
+			onlyB = append(onlyB, item)
+		}
+	}
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+	sort.Strings(both)
+	return onlyA, onlyB, both
+}
@@ -0,0 +1,88 @@
+package localrecall
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplitSets(t *testing.T) {
+	onlyA, onlyB, both := splitSets([]string{"c", "a", "b", "a"}, []string{"d", "b", "c"})
+	if !slices.Equal(onlyA, []string{"a"}) || !slices.Equal(onlyB, []string{"d"}) || !slices.Equal(both, []string{"b", "c"}) {
+		t.Errorf("Unexpected split %v %v %v", onlyA, onlyB, both)
+	}
+	onlyA, onlyB, both = splitSets(nil, nil)
+	if onlyA == nil || onlyB == nil || both == nil {
+		t.Error("Expected empty, non-nil lists")
+	}
+}
+
+func TestCompareCollectionsHandler(t *testing.T) {
+	server, client := newTestClient(t)
+	server.AddEntry("prod", "same.md", "same")
+	server.AddEntry("prod", "changed.md", "old")
+	server.AddEntry("prod", "removed.md", "gone")
+	server.AddEntry("staging", "same.md", "same")
+	server.AddEntry("staging", "changed.md", "newer")
+	server.AddEntry("staging", "added.md", "new")
+	server.AddSource("prod", "https://example.com/a", 60)
+	server.AddSource("prod", "https://example.com/both", 60)
+	server.AddSource("staging", "https://example.com/both", 120)
+	server.AddSource("staging", "https://example.com/b", 60)
+
+	params := map[string]interface{}{"collection_a": "prod", "collection_b": "staging"}
+	result := callToolJSON[collectionComparison](t, client, CompareCollectionsHandler, params)
+	if result.Match || result.Identical != 1 {
+		t.Errorf("Expected the collections to differ with 1 identical entry, got %+v", result)
+	}
+	if !slices.Equal(result.OnlyInA, []string{"removed.md"}) || !slices.Equal(result.OnlyInB, []string{"added.md"}) {
+		t.Errorf("Expected removed.md only in A and added.md only in B, got %v and %v", result.OnlyInA, result.OnlyInB)
+	}
+	if len(result.Differing) != 1 {
+		t.Fatalf("Expected one differing entry, got %+v", result.Differing)
+	}
+	if d := result.Differing[0]; d.Entry != "changed.md" || d.BytesA != 3 || d.BytesB != 5 || d.HashA != contentHash("old") || d.HashB == d.HashA {
+		t.Errorf("Unexpected difference %+v", d)
+	}
+
+	sources := result.Sources
+	if !slices.Equal(sources.OnlyInA, []string{"https://example.com/a"}) || !slices.Equal(sources.OnlyInB, []string{"https://example.com/b"}) {
+		t.Errorf("Unexpected source differences %+v", sources)
+	}
+	if len(sources.Differing) != 1 || sources.Differing[0].URL != "https://example.com/both" || sources.Differing[0].UpdateIntervalA == sources.Differing[0].UpdateIntervalB {
+		t.Errorf("Expected the shared source's interval to differ, got %+v", sources.Differing)
+	}
+
+	markdown, err := callTool(client, CompareCollectionsHandler, map[string]interface{}{"collection_a": "prod", "collection_b": "staging", "format": "markdown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"1 identical, 1 differing, 1 only in A, 1 only in B.", "### Only in A\n\n- removed.md", "- changed.md (3 → 5 bytes)"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Expected %q in the report, got\n%s", want, markdown)
+		}
+	}
+}
+
+func TestCompareCollectionsHandler_Match(t *testing.T) {
+	server, client := newTestClient(t)
+	for _, collection := range []string{"a", "b"} {
+		server.AddEntry(collection, "x.md", "x")
+		server.AddSource(collection, "https://example.com/feed", 60)
+	}
+
+	result := callToolJSON[collectionComparison](t, client, CompareCollectionsHandler, map[string]interface{}{"collection_a": "a", "collection_b": "b"})
+	if !result.Match || result.Identical != 1 {
+		t.Errorf("Expected the collections to match, got %+v", result)
+	}
+
+	server.FailRead = func(collection, _ string) bool { return collection == "b" }
+	result = callToolJSON[collectionComparison](t, client, CompareCollectionsHandler, map[string]interface{}{"collection_a": "a", "collection_b": "b"})
+	if result.Match || len(result.Errors) != 1 || result.Errors[0].Entry != "b/x.md" {
+		t.Errorf("Expected an unreadable entry to prevent a match, got %+v", result)
+	}
+
+	if _, err := callTool(client, CompareCollectionsHandler, map[string]interface{}{"collection_a": "a", "collection_b": "missing"}); err == nil {
+		t.Error("Expected a missing collection to fail")
+	}
+}
@@ -0,0 +1,257 @@
+package localrecall
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
+)
+
+// describer adapts a function describing a destructive call to
+// toolset.ConfirmFunc. Calls without a usable client have nothing to
+// describe; the handler reports the error.
+func describer(describe func(ctx context.Context, c *toolset.LocalRecallClient, params map[string]interface{}) string) toolset.ConfirmFunc {
+	return func(ctx context.Context, clientInterface interface{}, params map[string]interface{}) string {
+		c, err := getClient(clientInterface)
+		if err != nil {
+			return ""
+		}
+		return describe(ctx, c, params)
+	}
+}
+
+// entryCount returns the number of entries in a collection, or -1 if they
+// cannot be listed
+func entryCount(ctx context.Context, c *toolset.LocalRecallClient, collection string) int {
+	files, err := c.Client.ListFiles(ctx, collection)
+	if err != nil {
+		return -1
+	}
+	return len(files.Entries)
+}
+
+// entryExists reports whether an entry exists. Entries are assumed to exist
+// when the collection cannot be listed, so that the call is still confirmed.
+func entryExists(ctx context.Context, c *toolset.LocalRecallClient, collection, entry string) bool {
+	files, err := c.Client.ListFiles(ctx, collection)
+	return err != nil || slices.Contains(files.Entries, entry)
+}
+
+// matchingEntries returns the entries of a collection matching a glob
+// pattern, and how many of them also exist in the target collection. ok is
+// false if either collection cannot be listed.
+func matchingEntries(ctx context.Context, c *toolset.LocalRecallClient, collection, pattern, target string) (matched, existing int, ok bool) {
+	files, err := c.Client.ListFiles(ctx, collection)
+	if err != nil {
+		return 0, 0, false
+	}
+	targetFiles, err := c.Client.ListFiles(ctx, target)
+	if err != nil {
+		return 0, 0, false
+	}
+	for _, entry := range files.Entries {
+		if match, _ := path.Match(pattern, entry); match {
+			matched++
+			if slices.Contains(targetFiles.Entries, entry) {
+				existing++
+			}
+		}
+	}
+	return matched, existing, true
+}
+
+// entriesPhrase describes the entries of a collection at the start of a sentence
+func entriesPhrase(n int) string {
+	switch n {
+	case -1:
+		return "All entries"
+	case 1:
+		return "Its 1 entry"
+	default:
+		return fmt.Sprintf("Its %d entries", n)
+	}
+}
+
+// confirmResetCollection describes a reset_collection call
+func confirmResetCollection(ctx context.Context, c *toolset.LocalRecallClient, params map[string]interface{}) string {
+	name, _ := params["name"].(string)
+	return fmt.Sprintf("Reset collection %q? %s will be permanently deleted.", name, entriesPhrase(entryCount(ctx, c, name)))
+}
+
+// confirmDeleteEntry describes a delete_entry call
+func confirmDeleteEntry(ctx context.Context, c *toolset.LocalRecallClient, params map[string]interface{}) string {
+	collection, _ := params["collection_name"].(string)
+	entry, _ := params["entry"].(string)
+	return confirmEntryDeletion(ctx, c, collection, entry, "entry")
+}
+
+// confirmForget describes a forget call, by id or by tags
+func confirmForget(ctx context.Context, c *toolset.LocalRecallClient, params map[string]interface{}) string {
+	collection, _ := params["collection_name"].(string)
+	tags := handler.GetStringSliceParam(params, "tags")
+	if len(tags) == 0 {
+		id, _ := params["id"].(string)
+		return confirmEntryDeletion(ctx, c, collection, id, "memory note")
+	}
+	quoted := make([]string, len(tags))
+	for i, tag := range tags {
+		quoted[i] = fmt.Sprintf("%q", tag)
+	}
+	tagged := strings.Join(quoted, ", ")
+	ids, err := taggedMemories(ctx, c, collection, tags)
+	if err != nil {
+		return fmt.Sprintf("Delete the memory notes tagged %s from collection %q? They will be permanently deleted.", tagged, collection)
+	}
+	if len(ids) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Delete %d memory note(s) tagged %s from collection %q? They will be permanently deleted.", len(ids), tagged, collection)
+}
+
+// confirmEntryDeletion describes the deletion of an entry. Missing entries
+// have nothing to confirm; the call reports the error itself.
+func confirmEntryDeletion(ctx context.Context, c *toolset.LocalRecallClient, collection, entry, kind string) string {
+	files, err := c.Client.ListFiles(ctx, collection)
+	if err != nil {
+		return fmt.Sprintf("Delete %s %q from collection %q? It will be permanently deleted.", kind, entry, collection)
+	}
+	if !slices.Contains(files.Entries, entry) {
+		return ""
+	}
+	return fmt.Sprintf("Delete %s %q from collection %q? It will be permanently deleted; %d of the collection's %d entries will remain.",
+		kind, entry, collection, len(files.Entries)-1, len(files.Entries))
+}
+
+// confirmRemoveSource describes a remove_source call
+func confirmRemoveSource(_ context.Context, _ *toolset.LocalRecallClient, params map[string]interface{}) string {
+	collection, _ := params["collection_name"].(string)
+	url, _ := params["url"].(string)
+	return fmt.Sprintf("Remove external source %q from collection %q? LocalRecall will stop updating the collection from it.", url, collection)
+}
+
+// confirmRenameCollection describes a rename_collection call. Dry runs
+// change nothing.
+func confirmRenameCollection(ctx context.Context, c *toolset.LocalRecallClient, params map[string]interface{}) string {
+	if handler.GetBoolParam(params, "dry_run", false) {
+		return ""
+	}
+	source, _ := params["source_collection"].(string)
+	target, _ := params["target_collection"].(string)
+	return fmt.Sprintf("Rename collection %q to %q? %s will be copied to %q, then %q is reset and its sources removed.",
+		source, target, entriesPhrase(entryCount(ctx, c, source)), target, source)
+}
+
+// confirmAddDocument describes an add_document call replacing an existing entry
+func confirmAddDocument(ctx context.Context, c *toolset.LocalRecallClient, params map[string]interface{}) string {
+	collection, _ := params["collection_name"].(string)
+	filename, _ := params["filename"].(string)
+	if !entryExists(ctx, c, collection, filename) {
+		return ""
+	}
+	return fmt.Sprintf("Overwrite entry %q in collection %q? Its current content will be replaced.", filename, collection)
+}
+
+// confirmRestoreEntryVersion describes a restore_entry_version call replacing
+// the current content. Restoring a deleted entry has nothing to confirm.
+func confirmRestoreEntryVersion(ctx context.Context, c *toolset.LocalRecallClient, params map[string]interface{}) string {
+	collection, _ := params["collection_name"].(string)
+	entry, _ := params["entry"].(string)
+	if !entryExists(ctx, c, collection, entry) {
+		return ""
+	}
+	return fmt.Sprintf("Restore entry %q in collection %q to version %d? Its current content will be replaced.",
+		entry, collection, handler.GetIntParam(params, "version", 0))
+}
+
+// confirmFindDuplicates describes a find_duplicates call deleting duplicates
+func confirmFindDuplicates(ctx context.Context, c *toolset.LocalRecallClient, params map[string]interface{}) string {
+	if !handler.GetBoolParam(params, "delete_extras", false) || handler.GetBoolParam(params, "dry_run", true) {
+		return ""
+	}
+	collection, _ := params["collection_name"].(string)
+	message := fmt.Sprintf("Delete near-duplicate entries from collection %q? In each group of duplicates only the suggested keeper is kept; the others are permanently deleted.", collection)
+	if n := entryCount(ctx, c, collection); n >= 0 {
+		message += fmt.Sprintf(" The collection has %d entries; run with dry_run to review the deletions first.", n)
+	}
+	return message
+}
+
+// transferTarget returns the target of a copy_entry or move_entry call
+func transferTarget(params map[string]interface{}) (collection, entry, targetCollection, targetEntry string) {
+	collection, _ = params["collection_name"].(string)
+	entry, _ = params["entry"].(string)
+	return collection, entry, handler.GetStringParam(params, "target_collection", collection), handler.GetStringParam(params, "target_entry", entry)
+}
+
+// confirmCopyEntry describes a copy_entry call overwriting its target
+func confirmCopyEntry(ctx context.Context, c *toolset.LocalRecallClient, params map[string]interface{}) string {
+	collection, entry, targetCollection, targetEntry := transferTarget(params)
+	if !handler.GetBoolParam(params, "overwrite", false) || !entryExists(ctx, c, targetCollection, targetEntry) {
+		return ""
+	}
+	return fmt.Sprintf("Overwrite entry %q in collection %q with a copy of %q from %q? Its current content will be replaced.",
+		targetEntry, targetCollection, entry, collection)
+}
+
+// confirmMoveEntry describes a move_entry call
+func confirmMoveEntry(ctx context.Context, c *toolset.LocalRecallClient, params map[string]interface{}) string {
+	collection, entry, targetCollection, targetEntry := transferTarget(params)
+	message := fmt.Sprintf("Move entry %q from collection %q to %q in %q? It will be deleted from %q once the copy is verified.",
+		entry, collection, targetEntry, targetCollection, collection)
+	if handler.GetBoolParam(params, "overwrite", false) && entryExists(ctx, c, targetCollection, targetEntry) {
+		message += fmt.Sprintf(" The current content of %q in %q will be replaced.", targetEntry, targetCollection)
+	}
+	return message
+}
+
+// confirmCopyEntries describes a copy_entries call overwriting existing entries
+func confirmCopyEntries(ctx context.Context, c *toolset.LocalRecallClient, params map[string]interface{}) string {
+	if !handler.GetBoolParam(params, "overwrite", false) || handler.GetBoolParam(params, "dry_run", false) {
+		return ""
+	}
+	collection, _ := params["collection_name"].(string)
+	pattern, _ := params["pattern"].(string)
+	target, _ := params["target_collection"].(string)
+	matched, existing, ok := matchingEntries(ctx, c, collection, pattern, target)
+	if !ok {
+		return fmt.Sprintf("Copy the entries matching %q from collection %q to %q? Entries that already exist in %q will be replaced.", pattern, collection, target, target)
+	}
+	if existing == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Copy %d entries matching %q from collection %q to %q? %d of them are already in %q and will be replaced.",
+		matched, pattern, collection, target, existing, target)
+}
+
+// confirmMoveEntries describes a move_entries call
+func confirmMoveEntries(ctx context.Context, c *toolset.LocalRecallClient, params map[string]interface{}) string {
+	if handler.GetBoolParam(params, "dry_run", false) {
+		return ""
+	}
+	collection, _ := params["collection_name"].(string)
+	pattern, _ := params["pattern"].(string)
+	target, _ := params["target_collection"].(string)
+	overwrite := handler.GetBoolParam(params, "overwrite", false)
+	matched, existing, ok := matchingEntries(ctx, c, collection, pattern, target)
+	if !ok {
+		return fmt.Sprintf("Move the entries matching %q from collection %q to %q? They will be deleted from %q once copied.", pattern, collection, target, collection)
+	}
+	moved := matched
+	if !overwrite {
+		// Entries already in the target are skipped
+		moved -= existing
+	}
+	if moved == 0 {
+		return ""
+	}
+	message := fmt.Sprintf("Move %d of the %d entries matching %q from collection %q to %q? They will be deleted from %q once copied.",
+		moved, matched, pattern, collection, target, collection)
+	if overwrite && existing > 0 {
+		message += fmt.Sprintf(" %d of them are already in %q and will be replaced.", existing, target)
+	}
+	return message
+}
@@ -0,0 +1,74 @@
+package localrecall
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGetTools_DestructiveToolsDescribeCalls(t *testing.T) {
+	for _, tool := range (&Toolset{}).GetTools(nil) {
+		destructive := *tool.Tool.Annotations.DestructiveHint
+		if destructive != (tool.Confirm != nil) {
+			t.Errorf("%s: destructive=%v but has a describer=%v", tool.Tool.Name, destructive, tool.Confirm != nil)
+		}
+	}
+}
+
+func TestConfirmForget(t *testing.T) {
+	server, client := newTestClient(t)
+	id := addMemory(t, server, "mem", "2026-01-01T00:00:00Z", "a", []string{"project-x", "done"}, "a")
+	addMemory(t, server, "mem", "2026-01-02T00:00:00Z", "b", []string{"project-x", "done"}, "b")
+	addMemory(t, server, "mem", "2026-01-03T00:00:00Z", "c", []string{"project-x"}, "c")
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   string
+	}{
+		{
+			name:   "by id",
+			params: map[string]interface{}{"collection_name": "mem", "id": id},
+			want:   `Delete memory note "` + id + `" from collection "mem"? It will be permanently deleted; 2 of the collection's 3 entries will remain.`,
+		},
+		{
+			name:   "missing id",
+			params: map[string]interface{}{"collection_name": "mem", "id": "memory-missing.md"},
+		},
+		{
+			name:   "by tags",
+			params: map[string]interface{}{"collection_name": "mem", "tags": []interface{}{"project-x", "done"}},
+			want:   `Delete 2 memory note(s) tagged "project-x", "done" from collection "mem"? They will be permanently deleted.`,
+		},
+		{
+			name:   "no tagged notes",
+			params: map[string]interface{}{"collection_name": "mem", "tags": []interface{}{"other"}},
+		},
+		{
+			name:   "unreadable collection",
+			params: map[string]interface{}{"collection_name": "missing", "tags": []interface{}{"done"}},
+			want:   `Delete the memory notes tagged "done" from collection "missing"? They will be permanently deleted.`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := confirmForget(context.Background(), client, tt.params); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestConfirmRenameCollection(t *testing.T) {
+	server, client := newTestClient(t)
+	server.AddEntry("docs", "a.md", "a")
+
+	params := map[string]interface{}{"source_collection": "docs", "target_collection": "kb"}
+	want := `Rename collection "docs" to "kb"? Its 1 entry will be copied to "kb", then "docs" is reset and its sources removed.`
+	if got := confirmRenameCollection(context.Background(), client, params); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	params["dry_run"] = true
+	if got := confirmRenameCollection(context.Background(), client, params); got != "" {
+		t.Errorf("Expected nothing to confirm for a dry run, got %q", got)
+	}
+}
@@ -0,0 +1,177 @@
+package localrecall
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
+)
+
+// defaultDiffContextLines is the number of unchanged lines shown around each change
+const defaultDiffContextLines = 3
+
+// diffResult is the response of the diff_entries tool. A diff truncated to
+// the token budget keeps whole lines and counts the rest in OmittedLines.
+type diffResult struct {
+	From         string `json:"from" yaml:"from"`
+	To           string `json:"to" yaml:"to"`
+	Identical    bool   `json:"identical" yaml:"identical"`
+	Added        int    `json:"added" yaml:"added"`
+	Removed      int    `json:"removed" yaml:"removed"`
+	Hunks        int    `json:"hunks" yaml:"hunks"`
+	Approximate  bool   `json:"approximate,omitempty" yaml:"approximate,omitempty"`
+	Truncated    bool   `json:"truncated,omitempty" yaml:"truncated,omitempty"`
+	OmittedLines int    `json:"omitted_lines,omitempty" yaml:"omitted_lines,omitempty"`
+	Diff         string `json:"diff,omitempty" yaml:"diff,omitempty"`
+}
+
+// Markdown renders the diff as a summary followed by a diff code block
+func (d *diffResult) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Diff of %s → %s\n\n", d.From, d.To)
+	if d.Identical {
+		b.WriteString("The contents are identical.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "%d line(s) added, %d line(s) removed in %d hunk(s).\n\n", d.Added, d.Removed, d.Hunks)
+	b.WriteString("```diff\n")
+	b.WriteString(d.Diff)
+	if d.Diff != "" && !strings.HasSuffix(d.Diff, "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString("```\n")
+	if d.Truncated {
+		fmt.Fprintf(&b, "\n_The diff was truncated to fit the token budget: %d more line(s) not shown._\n", d.OmittedLines)
+	}
+	return b.String()
+}
+
+// diffSide is one side of a comparison
+type diffSide struct {
+	label   string
+	content string
+}
+
+// loadEntrySide reads an entry, or one of its archived versions when version > 0
+func loadEntrySide(ctx context.Context, client *toolset.LocalRecallClient, collection, entry string, version int) (diffSide, error) {
+	label := collection + "/" + entry
+	if version > 0 {
+		if err := checkVersioningEnabled(client); err != nil {
+			return diffSide{}, err
+		}
+		_, content, err := client.Versions.Get(collection, entry, version)
+		if err != nil {
+			return diffSide{}, err
+		}
+		return diffSide{label: fmt.Sprintf("%s@v%d", label, version), content: content}, nil
+	}
+	content, err := client.GetEntryContent(ctx, collection, entry)
+	if err != nil {
+		return diffSide{}, fmt.Errorf("failed to read %s: %w", label, err)
+	}
+	return diffSide{label: label, content: content.Content}, nil
+}
+
+// DiffEntriesHandler produces a unified diff between two entries, entry
+// versions, or an entry and a local file
+func DiffEntriesHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
+	client, err := getClient(clientInterface)
+	if err != nil {
+		return "", err
+	}
+
+	collectionName := handler.GetStringParam(params, "collection_name", "")
+
+	entry, err := handler.RequireStringParam(params, "entry")
+	if err != nil {
+		return "", err
+	}
+
+	version := handler.GetIntParam(params, "version", 0)
+	otherCollection := handler.GetStringParam(params, "other_collection", collectionName)
+	otherEntry := handler.GetStringParam(params, "other_entry", entry)
+	otherVersion := handler.GetIntParam(params, "other_version", 0)
+	filePath := handler.GetStringParam(params, "file_path", "")
+	contextLines := handler.GetIntParam(params, "context_lines", defaultDiffContextLines)
+	maxTokens := handler.GetIntParam(params, "max_tokens", 0)
+	format := handler.GetStringParam(params, "format", "json")
+
+	if contextLines < 0 {
+		return "", fmt.Errorf("context_lines must be non-negative")
+	}
+	if filePath != "" && (params["other_collection"] != nil || params["other_entry"] != nil || otherVersion > 0) {
+		return "", fmt.Errorf("cannot compare against both file_path and another entry")
+	}
+	if filePath == "" && otherCollection == collectionName && otherEntry == entry && version == otherVersion {
+		return "", fmt.Errorf("nothing to compare: specify other_entry, other_collection, a version or file_path")
+	}
+
+	from, err := loadEntrySide(ctx, client, collectionName, entry, version)
+	if err != nil {
+		return "", err
+	}
+
+	var to diffSide
+	if filePath != "" {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file: %w", err)
+		}
+		to = diffSide{label: filePath, content: string(data)}
+	} else {
+		to, err = loadEntrySide(ctx, client, otherCollection, otherEntry, otherVersion)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	result := diffContents(from, to, contextLines)
+	if maxTokens > 0 {
+		truncateDiff(result, maxTokens)
+	}
+
+	return handler.FormatResult(ctx, result, format)
+}
+
+// truncateDiff shortens the diff to fit maxTokens, keeping whole lines only
+func truncateDiff(result *diffResult, maxTokens int) {
+	truncated, removed := handler.TruncateToTokens(result.Diff, maxTokens)
+	if removed == 0 {
+		return
+	}
+	// Drop the partial last line
+	truncated = truncated[:strings.LastIndex(truncated, "\n")+1]
+	result.OmittedLines = strings.Count(result.Diff[len(truncated):], "\n")
+	if !strings.HasSuffix(result.Diff, "\n") {
+		result.OmittedLines++
+	}
+	result.Diff = truncated
+	result.Truncated = true
+}
+
+// diffContents compares two sides and summarizes the changes
+func diffContents(from, to diffSide, contextLines int) *diffResult {
+	result := &diffResult{From: from.label, To: to.label}
+	if from.content == to.content {
+		result.Identical = true
+		return result
+	}
+
+	ops, approximate := diffLines(splitLines(from.content), splitLines(to.content))
+	for _, op := range ops {
+		switch op.kind {
+		case '+':
+			result.Added++
+		case '-':
+			result.Removed++
+		}
+	}
+	result.Approximate = approximate
+	result.Diff, result.Hunks = unifiedDiff(from.label, to.label, ops, contextLines)
+	// Contents differing only in a trailing newline produce no line changes
+	result.Identical = result.Hunks == 0
+	return result
+}
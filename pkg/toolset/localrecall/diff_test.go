@@ -0,0 +1,103 @@
+package localrecall
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffResult_Markdown(t *testing.T) {
+	tests := []struct {
+		name   string
+		result diffResult
+		want   string
+	}{
+		{
+			name:   "identical",
+			result: diffResult{From: "a", To: "b", Identical: true},
+			want:   "## Diff of a → b\n\nThe contents are identical.\n",
+		},
+		{
+			name:   "complete",
+			result: diffResult{From: "a", To: "b", Added: 1, Removed: 1, Hunks: 1, Diff: "-x\n+y\n"},
+			want:   "## Diff of a → b\n\n1 line(s) added, 1 line(s) removed in 1 hunk(s).\n\n```diff\n-x\n+y\n```\n",
+		},
+		{
+			name:   "missing trailing newline",
+			result: diffResult{From: "a", To: "b", Added: 1, Hunks: 1, Diff: "+y"},
+			want:   "## Diff of a → b\n\n1 line(s) added, 0 line(s) removed in 1 hunk(s).\n\n```diff\n+y\n```\n",
+		},
+		{
+			name:   "truncated",
+			result: diffResult{From: "a", To: "b", Added: 3, Hunks: 1, Diff: "+x\n", Truncated: true, OmittedLines: 2},
+			want: "## Diff of a → b\n\n3 line(s) added, 0 line(s) removed in 1 hunk(s).\n\n```diff\n+x\n```\n" +
+				"\n_The diff was truncated to fit the token budget: 2 more line(s) not shown._\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.Markdown(); got != tt.want {
+				t.Errorf("Expected\n%s\ngot\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTruncateDiff(t *testing.T) {
+	diff := "--- a\n+++ b\n@@ -1,3 +1,3 @@\n-" + strings.Repeat("x", 40) + "\n+" + strings.Repeat("y", 40) + "\n"
+
+	t.Run("fits", func(t *testing.T) {
+		result := &diffResult{Diff: diff}
+		truncateDiff(result, 1000)
+		if result.Truncated || result.Diff != diff {
+			t.Errorf("Expected the diff to be kept, got %+v", result)
+		}
+	})
+
+	t.Run("cut mid-line", func(t *testing.T) {
+		// 10 tokens (40 characters) keep the header and part of the removed line
+		result := &diffResult{Diff: diff}
+		truncateDiff(result, 10)
+		if !result.Truncated || result.Diff != "--- a\n+++ b\n@@ -1,3 +1,3 @@\n" || result.OmittedLines != 2 {
+			t.Errorf("Expected the header lines only, got %q (%d omitted)", result.Diff, result.OmittedLines)
+		}
+	})
+
+	t.Run("first line too long", func(t *testing.T) {
+		result := &diffResult{Diff: diff}
+		truncateDiff(result, 1)
+		if !result.Truncated || result.Diff != "" || result.OmittedLines != 5 {
+			t.Errorf("Expected nothing to be kept, got %q (%d omitted)", result.Diff, result.OmittedLines)
+		}
+	})
+}
+
+func TestDiffEntriesHandler(t *testing.T) {
+	server, client := newTestClient(t)
+	server.AddEntry("docs", "a.md", "one\ntwo\nthree\n")
+	server.AddEntry("docs", "b.md", "one\n2\nthree\n")
+
+	result := callToolJSON[diffResult](t, client, DiffEntriesHandler, map[string]interface{}{
+		"collection_name": "docs",
+		"entry":           "a.md",
+		"other_entry":     "b.md",
+	})
+	if result.Added != 1 || result.Removed != 1 || result.Hunks != 1 || result.Truncated {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if !strings.Contains(result.Diff, "-two\n+2\n") {
+		t.Errorf("Expected the changed line in the diff, got %q", result.Diff)
+	}
+
+	truncated := callToolJSON[diffResult](t, client, DiffEntriesHandler, map[string]interface{}{
+		"collection_name": "docs",
+		"entry":           "a.md",
+		"other_entry":     "b.md",
+		"max_tokens":      10,
+	})
+	if !truncated.Truncated || !strings.HasSuffix(truncated.Diff, "\n") || truncated.OmittedLines == 0 {
+		t.Errorf("Expected a diff truncated on a line boundary, got %+v", truncated)
+	}
+	if !strings.HasPrefix(result.Diff, truncated.Diff) {
+		t.Errorf("Expected the truncated diff to be a prefix of the full diff, got %q", truncated.Diff)
+	}
+}
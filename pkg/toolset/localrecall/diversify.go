@@ -0,0 +1,130 @@
+package localrecall
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// overFetchFactor is how many candidates are requested per result when diversifying
+	overFetchFactor = 3
+	// maxOverFetch caps the number of candidates requested from LocalRecall
+	maxOverFetch = 100
+	// defaultMMRLambda balances relevance (1) against diversity (0)
+	defaultMMRLambda = 0.5
+)
+
+// diversifyOptions controls how search hits are re-ranked
+type diversifyOptions struct {
+	MMR          bool    // apply Maximal Marginal Relevance re-ranking
+	Lambda       float64 // relevance/diversity trade-off for MMR
+	MaxPerSource int     // maximum hits per source entry (0 = unlimited)
+}
+
+// enabled reports whether any re-ranking has been requested
+func (o diversifyOptions) enabled() bool {
+	return o.MMR || o.MaxPerSource > 0
+}
+
+// overFetch returns the number of candidates to request for k final results
+func overFetch(k int) int {
+	n := k * overFetchFactor
+	if n > maxOverFetch {
+		n = maxOverFetch
+	}
+	if n < k {
+		n = k
+	}
+	return n
+}
+
+// diversifyHits selects up to k hits from the candidates, applying MMR and the
+// per-source cap. Candidates are expected in descending relevance order.
+func diversifyHits(hits []map[string]interface{}, k int, opts diversifyOptions) []map[string]interface{} {
+	if k <= 0 || len(hits) == 0 {
+		return hits
+	}
+
+	tokens := make([]map[string]struct{}, len(hits))
+	for i, hit := range hits {
+		tokens[i] = tokenSet(hitContent(hit))
+	}
+
+	selected := make([]int, 0, k)
+	used := make([]bool, len(hits))
+	perSource := make(map[string]int)
+
+	for len(selected) < k {
+		best := -1
+		bestScore := 0.0
+		for i, hit := range hits {
+			if used[i] {
+				continue
+			}
+			if opts.MaxPerSource > 0 {
+				if src := hitSource(hit); src != "" && perSource[src] >= opts.MaxPerSource {
+					continue
+				}
+			}
+
+			score := hitSimilarity(hit)
+			if opts.MMR {
+				redundancy := 0.0
+				for _, j := range selected {
+					if sim := jaccard(tokens[i], tokens[j]); sim > redundancy {
+						redundancy = sim
+					}
+				}
+				score = opts.Lambda*score - (1-opts.Lambda)*redundancy
+			}
+
+			if best == -1 || score > bestScore {
+				best = i
+				bestScore = score
+			}
+		}
+		if best == -1 {
+			break
+		}
+		used[best] = true
+		selected = append(selected, best)
+		if src := hitSource(hits[best]); src != "" {
+			perSource[src]++
+		}
+	}
+
+	result := make([]map[string]interface{}, 0, len(selected))
+	for _, i := range selected {
+		result = append(result, hits[i])
+	}
+	return result
+}
+
+// tokenSet splits text into a set of lower-cased word tokens
+func tokenSet(text string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		set[word] = struct{}{}
+	}
+	return set
+}
+
+// jaccard returns the Jaccard similarity of two token sets
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	intersection := 0
+	for tok := range a {
+		if _, ok := b[tok]; ok {
+			intersection++
+		}
+	}
+	union := len(a) + len(b) - intersection
+	return float64(intersection) / float64(union)
+}
@@ -0,0 +1,111 @@
+package localrecall
+
+import (
+	"slices"
+	"testing"
+)
+
+// scoredHit builds a search hit with an ID, content, score and optional source
+func scoredHit(id, content string, similarity float64, source string) map[string]interface{} {
+	hit := map[string]interface{}{"ID": id, "Content": content, "Similarity": similarity}
+	if source != "" {
+		hit["Metadata"] = map[string]interface{}{"source": source}
+	}
+	return hit
+}
+
+// hitIDs returns the IDs of hits in order
+func hitIDs(hits []map[string]interface{}) []string {
+	ids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit["ID"].(string))
+	}
+	return ids
+}
+
+func TestDiversifyHits_MMR(t *testing.T) {
+	hits := []map[string]interface{}{
+		scoredHit("a", "alpha beta gamma", 0.9, ""),
+		scoredHit("b", "Alpha, beta and gamma", 0.85, ""),
+		scoredHit("c", "delta epsilon zeta", 0.6, ""),
+	}
+	tests := []struct {
+		name string
+		opts diversifyOptions
+		want []string
+	}{
+		// b repeats a: 0.5*0.85 - 0.5*0.75 < 0.5*0.6 - 0
+		{name: "balanced", opts: diversifyOptions{MMR: true, Lambda: 0.5}, want: []string{"a", "c"}},
+		{name: "relevance only", opts: diversifyOptions{MMR: true, Lambda: 1}, want: []string{"a", "b"}},
+		{name: "disabled", opts: diversifyOptions{}, want: []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hitIDs(diversifyHits(hits, 2, tt.opts))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDiversifyHits_MaxPerSource(t *testing.T) {
+	hits := []map[string]interface{}{
+		scoredHit("x1", "one", 0.9, "x.md"),
+		scoredHit("x2", "two", 0.8, "x.md"),
+		scoredHit("x3", "three", 0.7, "x.md"),
+		scoredHit("y1", "four", 0.6, "y.md"),
+		scoredHit("n1", "five", 0.5, ""),
+		scoredHit("n2", "six", 0.4, ""),
+	}
+	tests := []struct {
+		name string
+		k    int
+		max  int
+		want []string
+	}{
+		{name: "one per source", k: 2, max: 1, want: []string{"x1", "y1"}},
+		// Hits without a source are not capped
+		{name: "fewer than k left", k: 5, max: 1, want: []string{"x1", "y1", "n1", "n2"}},
+		{name: "two per source", k: 4, max: 2, want: []string{"x1", "x2", "y1", "n1"}},
+		{name: "unlimited", k: 3, max: 0, want: []string{"x1", "x2", "x3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hitIDs(diversifyHits(hits, tt.k, diversifyOptions{MaxPerSource: tt.max}))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{a: "", b: "", want: 0},
+		{a: "one two", b: "", want: 0},
+		{a: "one two", b: "Two, ONE!", want: 1},
+		{a: "one two three", b: "three four", want: 0.25},
+	}
+	for _, tt := range tests {
+		if got := jaccard(tokenSet(tt.a), tokenSet(tt.b)); got != tt.want {
+			t.Errorf("jaccard(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestOverFetch(t *testing.T) {
+	tests := []struct{ k, want int }{
+		{k: 5, want: 15},
+		{k: 40, want: maxOverFetch},
+		{k: 150, want: 150},
+	}
+	for _, tt := range tests {
+		if got := overFetch(tt.k); got != tt.want {
+			t.Errorf("overFetch(%d) = %d, want %d", tt.k, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package localrecall
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
+)
+
+const (
+	// defaultDuplicateThreshold is the minimum estimated similarity for near-duplicates
+	defaultDuplicateThreshold = 0.8
+	// defaultDuplicateMaxEntries limits the number of entries compared per call
+	defaultDuplicateMaxEntries = 500
+)
+
+// duplicatesResult is the response of the find_duplicates tool
+type duplicatesResult struct {
+	Collection      string           `json:"collection" yaml:"collection"`
+	Threshold       float64          `json:"threshold" yaml:"threshold"`
+	EntriesTotal    int              `json:"entries_total" yaml:"entries_total"`
+	EntriesCompared int              `json:"entries_compared" yaml:"entries_compared"`
+	Truncated       bool             `json:"truncated" yaml:"truncated"`
+	Groups          []duplicateGroup `json:"groups" yaml:"groups"`
+	Count           int              `json:"count" yaml:"count"`
+	DryRun          bool             `json:"dry_run" yaml:"dry_run"`
+	Deleted         []string         `json:"deleted,omitempty" yaml:"deleted,omitempty"`
+	WouldDelete     []string         `json:"would_delete,omitempty" yaml:"would_delete,omitempty"`
+	Errors          []entryError     `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// duplicateGroup is a cluster of near-identical entries
+type duplicateGroup struct {
+	Keeper  string            `json:"keeper" yaml:"keeper"`
+	Extras  []duplicateMember `json:"extras" yaml:"extras"`
+	Members int               `json:"members" yaml:"members"`
+}
+
+// duplicateMember is an entry that duplicates its group's keeper
+type duplicateMember struct {
+	Entry      string  `json:"entry" yaml:"entry"`
+	Similarity float64 `json:"similarity" yaml:"similarity"`
+	Bytes      int     `json:"bytes" yaml:"bytes"`
+}
+
+// FindDuplicatesHandler handles near-duplicate detection requests
+func FindDuplicatesHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
+	client, err := getClient(clientInterface)
+	if err != nil {
+		return "", err
+	}
+
+	collectionName := handler.GetStringParam(params, "collection_name", "")
+	threshold := handler.GetFloat64Param(params, "threshold", defaultDuplicateThreshold)
+	maxEntries := handler.GetIntParam(params, "max_entries", defaultDuplicateMaxEntries)
+	deleteExtras := handler.GetBoolParam(params, "delete_extras", false)
+	dryRun := handler.GetBoolParam(params, "dry_run", true)
+	format := handler.GetStringParam(params, "format", "json")
+
+	if threshold <= 0 || threshold > 1 {
+		return "", fmt.Errorf("threshold must be greater than 0 and at most 1")
+	}
+
+	files, err := client.Client.ListFiles(ctx, collectionName)
+	if err != nil {
+		return "", fmt.Errorf("list files failed: %w", err)
+	}
+
+	result := &duplicatesResult{
+		Collection:   collectionName,
+		Threshold:    threshold,
+		EntriesTotal: len(files.Entries),
+		Groups:       []duplicateGroup{},
+		DryRun:       dryRun,
+	}
+	entries := files.Entries
+	if maxEntries > 0 && len(entries) > maxEntries {
+		entries = entries[:maxEntries]
+		result.Truncated = true
+	}
+
+	// Sketch every readable, non-empty entry
+	var names []string
+	var sizes []int
+	var sigs []minHashSignature
+	for _, fetched := range fetchEntries(ctx, client, collectionName, entries) {
+		if fetched.Err != nil {
+			result.Errors = append(result.Errors, entryError{Entry: fetched.Entry, Error: fetched.Err.Error()})
+			continue
+		}
+		if strings.TrimSpace(fetched.Content.Content) == "" {
+			continue
+		}
+		names = append(names, fetched.Entry)
+		sizes = append(sizes, len(fetched.Content.Content))
+		sigs = append(sigs, minHash(fetched.Content.Content))
+	}
+	result.EntriesCompared = len(names)
+
+	for _, members := range groupDuplicates(names, sizes, sigs, threshold) {
+		keeper := members[0]
+		group := duplicateGroup{Keeper: names[keeper], Members: len(members)}
+		for _, m := range members[1:] {
+			group.Extras = append(group.Extras, duplicateMember{
+				Entry:      names[m],
+				Similarity: sigs[keeper].similarity(&sigs[m]),
+				Bytes:      sizes[m],
+			})
+		}
+		sort.Slice(group.Extras, func(i, j int) bool {
+			return group.Extras[i].Entry < group.Extras[j].Entry
+		})
+		result.Groups = append(result.Groups, group)
+	}
+	sort.Slice(result.Groups, func(i, j int) bool {
+		return result.Groups[i].Keeper < result.Groups[j].Keeper
+	})
+	result.Count = len(result.Groups)
+
+	if deleteExtras {
+		for _, group := range result.Groups {
+			for _, extra := range group.Extras {
+				if dryRun {
+					result.WouldDelete = append(result.WouldDelete, extra.Entry)
+					continue
+				}
+				if _, err := client.Client.DeleteEntry(ctx, collectionName, extra.Entry); err != nil {
+					result.Errors = append(result.Errors, entryError{Entry: extra.Entry, Error: fmt.Sprintf("delete failed: %v", err)})
+					continue
+				}
+				client.InvalidateEntry(collectionName, extra.Entry)
+				forgetExpiry(client, collectionName, extra.Entry)
+				result.Deleted = append(result.Deleted, extra.Entry)
+			}
+		}
+	}
+
+	return handler.FormatResult(ctx, result, format)
+}
+
+// groupDuplicates returns the groups of near-duplicate entries, each starting
+// with its keeper. Pairs above the threshold are clustered transitively, then
+// every cluster is split around keepers so that each extra is at least
+// threshold-similar to its own keeper: with A~B and B~C, C is not deleted in
+// favour of A just because both resemble B.
+func groupDuplicates(names []string, sizes []int, sigs []minHashSignature, threshold float64) [][]int {
+	uf := newUnionFind(len(names))
+	for i := range sigs {
+		for j := i + 1; j < len(sigs); j++ {
+			if sigs[i].similarity(&sigs[j]) >= threshold {
+				uf.union(i, j)
+			}
+		}
+	}
+	clusters := make(map[int][]int)
+	for i := range names {
+		root := uf.find(i)
+		clusters[root] = append(clusters[root], i)
+	}
+
+	var groups [][]int
+	for _, remaining := range clusters {
+		for len(remaining) >= 2 {
+			keeper := suggestKeeper(remaining, names, sizes)
+			group := []int{keeper}
+			var rest []int
+			for _, m := range remaining {
+				if m == keeper {
+					continue
+				}
+				if sigs[keeper].similarity(&sigs[m]) >= threshold {
+					group = append(group, m)
+				} else {
+					rest = append(rest, m)
+				}
+			}
+			if len(group) >= 2 {
+				groups = append(groups, group)
+			}
+			remaining = rest
+		}
+	}
+	return groups
+}
+
+// suggestKeeper picks the entry to keep from a duplicate cluster: the largest
+// (most complete) entry, then the shortest name, then alphabetical order.
+func suggestKeeper(members []int, names []string, sizes []int) int {
+	best := members[0]
+	for _, m := range members[1:] {
+		switch {
+		case sizes[m] != sizes[best]:
+			if sizes[m] > sizes[best] {
+				best = m
+			}
+		case len(names[m]) != len(names[best]):
+			if len(names[m]) < len(names[best]) {
+				best = m
+			}
+		case names[m] < names[best]:
+			best = m
+		}
+	}
+	return best
+}
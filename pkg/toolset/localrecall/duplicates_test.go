@@ -0,0 +1,144 @@
+package localrecall
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMinHash_Similarity(t *testing.T) {
+	text := "the quick brown fox jumps over the lazy dog while the cat sleeps in the warm sun"
+	a := minHash(text)
+	b := minHash(strings.ToUpper(text) + "!")
+	if sim := a.similarity(&b); sim != 1 {
+		t.Errorf("Expected similarity 1 for texts differing in case and punctuation, got %v", sim)
+	}
+
+	c := minHash("completely unrelated words about databases indexes queries and storage engines")
+	if sim := a.similarity(&c); sim > 0.1 {
+		t.Errorf("Expected similarity near 0 for unrelated texts, got %v", sim)
+	}
+
+	d := minHash(text + " and the bird sings")
+	sim := a.similarity(&d)
+	if sim <= 0.5 || sim >= 1 {
+		t.Errorf("Expected similarity between 0.5 and 1 for an extended text, got %v", sim)
+	}
+}
+
+func TestMinHash_ShortAndEmptyTexts(t *testing.T) {
+	a := minHash("hello world")
+	b := minHash("Hello, world.")
+	if sim := a.similarity(&b); sim != 1 {
+		t.Errorf("Expected texts shorter than a shingle to match, got %v", sim)
+	}
+
+	empty := minHash("  ")
+	for i, v := range empty {
+		if v != ^uint64(0) {
+			t.Fatalf("Expected an empty text to leave slot %d unset, got %d", i, v)
+		}
+	}
+}
+
+func TestUnionFind(t *testing.T) {
+	uf := newUnionFind(5)
+	uf.union(0, 1)
+	uf.union(3, 4)
+	uf.union(1, 4)
+
+	for _, i := range []int{1, 3, 4} {
+		if uf.find(i) != uf.find(0) {
+			t.Errorf("Expected %d in the set of 0", i)
+		}
+	}
+	if uf.find(2) == uf.find(0) {
+		t.Error("Expected 2 to stay in its own set")
+	}
+}
+
+func TestSuggestKeeper(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		sizes []int
+		want  string
+	}{
+		{name: "largest", names: []string{"a.txt", "b.txt", "c.txt"}, sizes: []int{10, 30, 20}, want: "b.txt"},
+		{name: "shortest name on equal size", names: []string{"copy-of-a.txt", "a.txt"}, sizes: []int{10, 10}, want: "a.txt"},
+		{name: "alphabetical on equal size and name length", names: []string{"b.txt", "a.txt"}, sizes: []int{10, 10}, want: "a.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			members := make([]int, len(tt.names))
+			for i := range members {
+				members[i] = i
+			}
+			if got := tt.names[suggestKeeper(members, tt.names, tt.sizes)]; got != tt.want {
+				t.Errorf("Expected keeper %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+// blendSignatures returns a signature equal to a in its first n slots and to b in the others
+func blendSignatures(a, b minHashSignature, n int) minHashSignature {
+	sig := b
+	copy(sig[:n], a[:n])
+	return sig
+}
+
+// distinctSignature returns a signature sharing no slot with others made from a different seed
+func distinctSignature(seed uint64) minHashSignature {
+	var sig minHashSignature
+	for i := range sig {
+		sig[i] = seed<<32 | uint64(i)
+	}
+	return sig
+}
+
+func TestGroupDuplicates_NotTransitive(t *testing.T) {
+	// a~b and b~c at 0.5, but a and c share nothing
+	a := distinctSignature(1)
+	c := distinctSignature(2)
+	b := blendSignatures(a, c, minHashSize/2)
+
+	names := []string{"a.txt", "b.txt", "c.txt"}
+	sizes := []int{300, 200, 100}
+	groups := groupDuplicates(names, sizes, []minHashSignature{a, b, c}, 0.4)
+
+	if len(groups) != 1 {
+		t.Fatalf("Expected 1 group, got %v", groups)
+	}
+	if !slices.Equal(groups[0], []int{0, 1}) {
+		t.Errorf("Expected group [a.txt b.txt] kept by a.txt, got %v", groups[0])
+	}
+}
+
+func TestGroupDuplicates_SplitsClusterAroundKeepers(t *testing.T) {
+	// a~b, b~c, c~d: a keeps b, then c keeps d
+	a := distinctSignature(1)
+	c := distinctSignature(2)
+	b := blendSignatures(a, c, minHashSize/2)
+	d := blendSignatures(c, distinctSignature(3), minHashSize/2)
+
+	names := []string{"a.txt", "b.txt", "c.txt", "d.txt"}
+	sizes := []int{400, 300, 200, 100}
+	groups := groupDuplicates(names, sizes, []minHashSignature{a, b, c, d}, 0.4)
+
+	slices.SortFunc(groups, func(x, y []int) int { return x[0] - y[0] })
+	if len(groups) != 2 || !slices.Equal(groups[0], []int{0, 1}) || !slices.Equal(groups[1], []int{2, 3}) {
+		t.Errorf("Expected groups [[0 1] [2 3]], got %v", groups)
+	}
+}
+
+func TestGroupDuplicates_KeeperFirst(t *testing.T) {
+	sig := distinctSignature(1)
+	names := []string{"small.txt", "large.txt", "other.txt"}
+	sizes := []int{10, 50, 20}
+	groups := groupDuplicates(names, sizes, []minHashSignature{sig, sig, distinctSignature(2)}, 0.8)
+
+	if len(groups) != 1 || !slices.Equal(groups[0], []int{1, 0}) {
+		t.Errorf("Expected group [1 0] led by the largest entry, got %v", groups)
+	}
+}
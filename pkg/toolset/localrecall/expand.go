@@ -0,0 +1,166 @@
+package localrecall
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	lrclient "github.com/futuretea/localrecall-mcp-server/pkg/client"
+)
+
+const (
+	// expandChunk attaches context_chars of surrounding text to each hit
+	expandChunk = "chunk"
+	// expandEntry attaches the full parent entry to the first hit of each source
+	expandEntry = "entry"
+
+	// defaultMaxContextChars bounds the total amount of expanded text per call
+	defaultMaxContextChars = 20000
+)
+
+// expandOptions controls how search hits are expanded with parent entry text
+type expandOptions struct {
+	Mode         string // expandChunk or expandEntry
+	ContextChars int    // characters before and after the chunk in chunk mode
+	MaxChars     int    // total budget for expanded text across all hits
+}
+
+// enabled reports whether any expansion has been requested
+func (o expandOptions) enabled() bool {
+	return o.Mode == expandEntry || o.ContextChars > 0
+}
+
+// entryFetcher fetches entry contents once per call
+type entryFetcher struct {
+	client     *lrclient.Client
+	collection string
+	entries    map[string]*lrclient.EntryContent
+	errors     map[string]error
+}
+
+func newEntryFetcher(client *lrclient.Client, collection string) *entryFetcher {
+	return &entryFetcher{
+		client:     client,
+		collection: collection,
+		entries:    make(map[string]*lrclient.EntryContent),
+		errors:     make(map[string]error),
+	}
+}
+
+// get returns the content of an entry, fetching it on first use
+func (f *entryFetcher) get(ctx context.Context, entry string) (*lrclient.EntryContent, error) {
+	if content, ok := f.entries[entry]; ok {
+		return content, nil
+	}
+	if err, ok := f.errors[entry]; ok {
+		return nil, err
+	}
+	content, err := f.client.GetEntryContent(ctx, f.collection, entry)
+	if err != nil {
+		f.errors[entry] = err
+		return nil, err
+	}
+	f.entries[entry] = content
+	return content, nil
+}
+
+// expandHits adds a "Context" field with surrounding entry text to each hit.
+// Hits without a known source, or whose chunk cannot be located in the entry,
+// are left unchanged. Once the budget is spent the remaining hits are marked
+// with "ContextTruncated".
+func expandHits(ctx context.Context, fetcher *entryFetcher, hits []map[string]interface{}, opts expandOptions) {
+	budget := opts.MaxChars
+	if budget <= 0 {
+		budget = defaultMaxContextChars
+	}
+	attached := make(map[string]bool)
+
+	for _, hit := range hits {
+		source := hitSource(hit)
+		if source == "" {
+			continue
+		}
+		if opts.Mode == expandEntry && attached[source] {
+			continue
+		}
+
+		entry, err := fetcher.get(ctx, source)
+		if err != nil {
+			hit["ContextError"] = fmt.Sprintf("failed to fetch entry: %v", err)
+			continue
+		}
+
+		var text string
+		if opts.Mode == expandEntry {
+			text = entry.Content
+		} else {
+			var found bool
+			text, found = surroundingText(entry.Content, hitContent(hit), opts.ContextChars)
+			if !found {
+				continue
+			}
+		}
+
+		if budget <= 0 {
+			hit["ContextTruncated"] = true
+			continue
+		}
+		if utf8.RuneCountInString(text) > budget {
+			text = truncateRunes(text, budget)
+			hit["ContextTruncated"] = true
+		}
+		budget -= utf8.RuneCountInString(text)
+		hit["Context"] = text
+		attached[source] = true
+	}
+}
+
+// surroundingText returns the chunk together with up to n characters on
+// either side of it within content. The second return value is false when the
+// chunk cannot be located.
+func surroundingText(content, chunk string, n int) (string, bool) {
+	needle := strings.TrimSpace(chunk)
+	if needle == "" {
+		return "", false
+	}
+	start := strings.Index(content, needle)
+	if start < 0 {
+		return "", false
+	}
+	end := start + len(needle)
+
+	from := start
+	for i := 0; i < n && from > 0; i++ {
+		_, size := utf8.DecodeLastRuneInString(content[:from])
+		from -= size
+	}
+	to := end
+	for i := 0; i < n && to < len(content); i++ {
+		_, size := utf8.DecodeRuneInString(content[to:])
+		to += size
+	}
+	return content[from:to], true
+}
+
+// truncateRunes truncates s to at most n characters
+func truncateRunes(s string, n int) string {
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+	return s
+}
+
+// truncateUTF8 truncates s to at most n bytes without splitting a character
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
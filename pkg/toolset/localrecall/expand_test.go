@@ -0,0 +1,114 @@
+package localrecall
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSurroundingText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		chunk   string
+		n       int
+		want    string
+		found   bool
+	}{
+		{name: "middle", content: "aaaaXXbbbb", chunk: "XX", n: 2, want: "aaXXbb", found: true},
+		{name: "start of entry", content: "XXbbbb", chunk: "XX", n: 3, want: "XXbbb", found: true},
+		{name: "end of entry", content: "aaaaXX", chunk: "XX", n: 3, want: "aaaXX", found: true},
+		{name: "window larger than entry", content: "aXb", chunk: "X", n: 10, want: "aXb", found: true},
+		{name: "chunk whitespace trimmed", content: "aXb", chunk: "  X\n", n: 1, want: "aXb", found: true},
+		{name: "multibyte", content: "日本語XX中文字", chunk: "XX", n: 2, want: "本語XX中文", found: true},
+		{name: "zero window", content: "日本XX中文", chunk: "XX", n: 0, want: "XX", found: true},
+		{name: "not found", content: "abc", chunk: "XX", n: 2},
+		{name: "empty chunk", content: "abc", chunk: " ", n: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := surroundingText(tt.content, tt.chunk, tt.n)
+			if got != tt.want || found != tt.found {
+				t.Errorf("Expected (%q, %v), got (%q, %v)", tt.want, tt.found, got, found)
+			}
+		})
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	if got := truncateRunes("日本語", 2); got != "日本" {
+		t.Errorf("Expected 2 characters, got %q", got)
+	}
+	if got := truncateRunes("abc", 5); got != "abc" {
+		t.Errorf("Expected the string to be kept, got %q", got)
+	}
+	if got := truncateRunes("abc", 0); got != "" {
+		t.Errorf("Expected an empty string, got %q", got)
+	}
+}
+
+func TestExpandHits(t *testing.T) {
+	server, client := newTestClient(t)
+	server.AddEntry("docs", "a.md", "αβγ first chunk δεζ")
+	server.AddEntry("docs", "b.md", "ηθι second chunk κλμ")
+	fetcher := newEntryFetcher(client.Client, "docs")
+
+	newHits := func() []map[string]interface{} {
+		return []map[string]interface{}{
+			{"Content": "first chunk", "Metadata": map[string]interface{}{"source": "a.md"}},
+			{"Content": "second chunk", "Metadata": map[string]interface{}{"source": "b.md"}},
+			{"Content": "first chunk", "Metadata": map[string]interface{}{"source": "a.md"}},
+		}
+	}
+
+	t.Run("chunk", func(t *testing.T) {
+		hits := newHits()
+		expandHits(context.Background(), fetcher, hits, expandOptions{Mode: expandChunk, ContextChars: 2})
+		if hits[0]["Context"] != "γ first chunk δ" || hits[1]["Context"] != "ι second chunk κ" {
+			t.Errorf("Unexpected contexts %q, %q", hits[0]["Context"], hits[1]["Context"])
+		}
+		if hits[2]["Context"] != "γ first chunk δ" {
+			t.Errorf("Expected chunk mode to expand every hit, got %q", hits[2]["Context"])
+		}
+	})
+
+	t.Run("entry", func(t *testing.T) {
+		hits := newHits()
+		expandHits(context.Background(), fetcher, hits, expandOptions{Mode: expandEntry})
+		if hits[0]["Context"] != "αβγ first chunk δεζ" || hits[1]["Context"] != "ηθι second chunk κλμ" {
+			t.Errorf("Unexpected contexts %q, %q", hits[0]["Context"], hits[1]["Context"])
+		}
+		if _, ok := hits[2]["Context"]; ok {
+			t.Error("Expected the entry to be attached once per source")
+		}
+	})
+
+	t.Run("budget runs out", func(t *testing.T) {
+		// The first entry takes 19 of 20 characters, the second is cut to 1
+		hits := newHits()
+		expandHits(context.Background(), fetcher, hits, expandOptions{Mode: expandEntry, MaxChars: 20})
+		if hits[0]["Context"] != "αβγ first chunk δεζ" || hits[0]["ContextTruncated"] != nil {
+			t.Errorf("Expected the first entry in full, got %q", hits[0]["Context"])
+		}
+		if hits[1]["Context"] != "η" || hits[1]["ContextTruncated"] != true {
+			t.Errorf("Expected the second entry cut to the remaining budget, got %q", hits[1]["Context"])
+		}
+
+		hits = newHits()
+		expandHits(context.Background(), fetcher, hits, expandOptions{Mode: expandChunk, ContextChars: 2, MaxChars: 13})
+		if hits[0]["Context"] != "γ first chunk" || hits[0]["ContextTruncated"] != true {
+			t.Errorf("Expected the budget to be spent by the first hit, got %+v", hits)
+		}
+		if _, ok := hits[1]["Context"]; ok || hits[2]["ContextTruncated"] != true {
+			t.Errorf("Expected the remaining hits to be marked truncated, got %+v", hits[1:])
+		}
+	})
+
+	t.Run("missing entry", func(t *testing.T) {
+		hits := []map[string]interface{}{{"Content": "x", "Metadata": map[string]interface{}{"source": "missing.md"}}}
+		expandHits(context.Background(), fetcher, hits, expandOptions{Mode: expandEntry})
+		if msg, _ := hits[0]["ContextError"].(string); !strings.Contains(msg, "failed to fetch entry") {
+			t.Errorf("Expected a fetch error, got %+v", hits[0])
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package localrecall
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/core/logging"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
+)
+
+// parseExpiry reads the ttl and expires_at parameters. The second return
+// value is false when neither is given.
+func parseExpiry(params map[string]interface{}, now time.Time) (time.Time, bool, error) {
+	_, hasTTL := params["ttl"]
+	expiresAt := handler.GetStringParam(params, "expires_at", "")
+
+	switch {
+	case hasTTL && expiresAt != "":
+		return time.Time{}, false, fmt.Errorf("cannot specify both ttl and expires_at")
+	case hasTTL:
+		ttl, err := parseTTL(params["ttl"])
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		return now.Add(ttl), true, nil
+	case expiresAt != "":
+		t, err := time.Parse(time.RFC3339, expiresAt)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("expires_at must be an RFC 3339 timestamp: %w", err)
+		}
+		if !t.After(now) {
+			return time.Time{}, false, fmt.Errorf("expires_at must be in the future")
+		}
+		return t, true, nil
+	}
+	return time.Time{}, false, nil
+}
+
+// parseTTL accepts a number of seconds or a duration string such as "90m",
+// "24h" or "7d"
+func parseTTL(val interface{}) (time.Duration, error) {
+	var ttl time.Duration
+	switch v := val.(type) {
+	case float64:
+		ttl = time.Duration(v * float64(time.Second))
+	case int:
+		ttl = time.Duration(v) * time.Second
+	case int64:
+		ttl = time.Duration(v) * time.Second
+	case string:
+		if days, ok := strings.CutSuffix(v, "d"); ok {
+			n, err := strconv.Atoi(days)
+			if err != nil {
+				return 0, fmt.Errorf("invalid ttl %q", v)
+			}
+			ttl = time.Duration(n) * 24 * time.Hour
+		} else if secs, err := strconv.Atoi(v); err == nil {
+			ttl = time.Duration(secs) * time.Second
+		} else {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return 0, fmt.Errorf("invalid ttl %q: %w", v, err)
+			}
+			ttl = d
+		}
+	default:
+		return 0, fmt.Errorf("ttl must be a number of seconds or a duration string")
+	}
+	if ttl <= 0 {
+		return 0, fmt.Errorf("ttl must be positive")
+	}
+	return ttl, nil
+}
+
+// checkExpiryEnabled returns an error if an expiry was requested but the
+// server has no expiry index configured
+func checkExpiryEnabled(client *toolset.LocalRecallClient, requested bool) error {
+	if requested && client.Expiry == nil {
+		return fmt.Errorf("entry expiry is not enabled on this server (set expiry_index_path)")
+	}
+	return nil
+}
+
+// scheduleExpiry records the expiry of a freshly added entry
+func scheduleExpiry(client *toolset.LocalRecallClient, collection, entry string, expiresAt time.Time) error {
+	if err := client.Expiry.Set(collection, entry, expiresAt); err != nil {
+		return fmt.Errorf("entry %s was added but its expiry could not be recorded: %w", entry, err)
+	}
+	return nil
+}
+
+// forgetExpiry drops the expiry record of a deleted entry. Failures only
+// leave a stale record, which the janitor cleans up, so they are not fatal.
+func forgetExpiry(client *toolset.LocalRecallClient, collection, entry string) {
+	if err := client.Expiry.Remove(collection, entry); err != nil {
+		logging.Warn("Failed to remove expiry of %s/%s: %v", collection, entry, err)
+	}
+}
@@ -0,0 +1,78 @@
+package localrecall
+
+import (
+	"context"
+	"iter"
+	"sync"
+
+	lrclient "github.com/futuretea/localrecall-mcp-server/pkg/client"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+)
+
+// fetchedEntry is the outcome of fetching a single entry
+type fetchedEntry struct {
+	Entry   string
+	Content *lrclient.EntryContent
+	Err     error
+}
+
+// entryError records an entry that could not be read
+type entryError struct {
+	Entry string `json:"entry" yaml:"entry"`
+	Error string `json:"error" yaml:"error"`
+}
+
+// fetchEntries fetches the contents of entries with bounded concurrency,
+// using the client's content cache. Results are returned in input order.
+func fetchEntries(ctx context.Context, client *toolset.LocalRecallClient, collection string, entries []string) []fetchedEntry {
+	results := make([]fetchedEntry, 0, len(entries))
+	for fetched := range streamEntries(ctx, client, collection, entries) {
+		results = append(results, fetched)
+	}
+	return results
+}
+
+// streamEntries fetches the contents of entries like fetchEntries, yielding
+// each in input order as soon as it and the entries before it are available.
+// Fetches start in input order, and those not yet finished are cancelled when
+// the caller stops iterating.
+func streamEntries(ctx context.Context, client *toolset.LocalRecallClient, collection string, entries []string) iter.Seq[fetchedEntry] {
+	return func(yield func(fetchedEntry) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		defer cancel()
+
+		results := make([]chan fetchedEntry, len(entries))
+		for i := range results {
+			results[i] = make(chan fetchedEntry, 1)
+		}
+
+		sem := make(chan struct{}, client.Concurrency())
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, entry := range entries {
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					results[i] <- fetchedEntry{Entry: entry, Err: ctx.Err()}
+					continue
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() { <-sem }()
+					content, err := client.GetEntryContent(ctx, collection, entry)
+					results[i] <- fetchedEntry{Entry: entry, Content: content, Err: err}
+				}()
+			}
+		}()
+
+		for _, result := range results {
+			if !yield(<-result) {
+				return
+			}
+		}
+	}
+}
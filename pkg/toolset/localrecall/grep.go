@@ -0,0 +1,176 @@
+package localrecall
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
+)
+
+const (
+	// defaultGrepMaxEntries limits the number of entries scanned per call
+	defaultGrepMaxEntries = 100
+	// defaultGrepMaxMatches limits the number of matching lines returned
+	defaultGrepMaxMatches = 50
+	// maxGrepLineChars shortens very long matched lines
+	maxGrepLineChars = 500
+)
+
+// grepResult is the response of the grep_entries tool
+type grepResult struct {
+	Collection     string       `json:"collection" yaml:"collection"`
+	Pattern        string       `json:"pattern" yaml:"pattern"`
+	EntriesTotal   int          `json:"entries_total" yaml:"entries_total"`
+	EntriesScanned int          `json:"entries_scanned" yaml:"entries_scanned"`
+	EntriesMatched int          `json:"entries_matched" yaml:"entries_matched"`
+	Matches        []grepMatch  `json:"matches" yaml:"matches"`
+	Count          int          `json:"count" yaml:"count"`
+	Truncated      bool         `json:"truncated" yaml:"truncated"`
+	Errors         []entryError `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// grepMatch is a single matching line
+type grepMatch struct {
+	Entry  string   `json:"entry" yaml:"entry"`
+	Line   int      `json:"line" yaml:"line"`
+	Text   string   `json:"text" yaml:"text"`
+	Before []string `json:"before,omitempty" yaml:"before,omitempty"`
+	After  []string `json:"after,omitempty" yaml:"after,omitempty"`
+}
+
+// GrepEntriesHandler handles lexical search across collection entries
+func GrepEntriesHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
+	client, err := getClient(clientInterface)
+	if err != nil {
+		return "", err
+	}
+
+	collectionName := handler.GetStringParam(params, "collection_name", "")
+
+	pattern, err := handler.RequireStringParam(params, "pattern")
+	if err != nil {
+		return "", err
+	}
+
+	literal := handler.GetBoolParam(params, "literal", false)
+	ignoreCase := handler.GetBoolParam(params, "ignore_case", false)
+	contextLines := handler.GetIntParam(params, "context_lines", 0)
+	entryPattern := handler.GetStringParam(params, "entry_pattern", "")
+	maxEntries := handler.GetIntParam(params, "max_entries", defaultGrepMaxEntries)
+	maxMatches := handler.GetIntParam(params, "max_matches", defaultGrepMaxMatches)
+	format := handler.GetStringParam(params, "format", "json")
+
+	expr := pattern
+	if literal {
+		expr = regexp.QuoteMeta(pattern)
+	}
+	if ignoreCase {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern: %w", err)
+	}
+	if entryPattern != "" {
+		if _, err := path.Match(entryPattern, ""); err != nil {
+			return "", fmt.Errorf("invalid entry_pattern: %w", err)
+		}
+	}
+
+	files, err := client.Client.ListFiles(ctx, collectionName)
+	if err != nil {
+		return "", fmt.Errorf("list files failed: %w", err)
+	}
+
+	entries := make([]string, 0, len(files.Entries))
+	for _, entry := range files.Entries {
+		if entryPattern != "" {
+			if ok, _ := path.Match(entryPattern, entry); !ok {
+				continue
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	result := &grepResult{
+		Collection:   collectionName,
+		Pattern:      pattern,
+		EntriesTotal: len(entries),
+		Matches:      []grepMatch{},
+	}
+	if maxEntries > 0 && len(entries) > maxEntries {
+		entries = entries[:maxEntries]
+		result.Truncated = true
+	}
+
+	for fetched := range streamEntries(ctx, client, collectionName, entries) {
+		result.EntriesScanned++
+		if fetched.Err != nil {
+			result.Errors = append(result.Errors, entryError{Entry: fetched.Entry, Error: fetched.Err.Error()})
+			continue
+		}
+
+		matches := grepLines(fetched.Entry, fetched.Content.Content, re, contextLines)
+		if len(matches) == 0 {
+			continue
+		}
+		result.EntriesMatched++
+		for _, m := range matches {
+			if maxMatches > 0 && len(result.Matches) >= maxMatches {
+				result.Truncated = true
+				break
+			}
+			result.Matches = append(result.Matches, m)
+		}
+
+		// Stop fetching once max_matches is reached; unscanned entries may hold more
+		if maxMatches > 0 && len(result.Matches) >= maxMatches {
+			if result.EntriesScanned < len(entries) {
+				result.Truncated = true
+			}
+			break
+		}
+	}
+	result.Count = len(result.Matches)
+
+	return handler.FormatResult(ctx, result, format)
+}
+
+// grepLines returns the lines of content matching re, with up to
+// contextLines of surrounding lines. Line numbers are 1-based.
+func grepLines(entry, content string, re *regexp.Regexp, contextLines int) []grepMatch {
+	lines := strings.Split(content, "\n")
+	var matches []grepMatch
+	for i, line := range lines {
+		if !re.MatchString(line) {
+			continue
+		}
+		m := grepMatch{
+			Entry: entry,
+			Line:  i + 1,
+			Text:  shortLine(line),
+		}
+		if contextLines > 0 {
+			for _, l := range lines[max(0, i-contextLines):i] {
+				m.Before = append(m.Before, shortLine(l))
+			}
+			for _, l := range lines[i+1 : min(len(lines), i+1+contextLines)] {
+				m.After = append(m.After, shortLine(l))
+			}
+		}
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+// shortLine trims a trailing carriage return and shortens very long lines
+func shortLine(line string) string {
+	line = strings.TrimSuffix(line, "\r")
+	if len(line) > maxGrepLineChars {
+		return truncateUTF8(line, maxGrepLineChars) + "…"
+	}
+	return line
+}
@@ -0,0 +1,130 @@
+package localrecall
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGrepLines(t *testing.T) {
+	content := "one\ntwo\r\nthree\nfour\nfive"
+	matches := grepLines("a.md", content, regexp.MustCompile("t"), 1)
+	if len(matches) != 2 {
+		t.Fatalf("Expected 2 matches, got %+v", matches)
+	}
+	two, three := matches[0], matches[1]
+	if two.Line != 2 || two.Text != "two" || strings.Join(two.Before, ",") != "one" || strings.Join(two.After, ",") != "three" {
+		t.Errorf("Unexpected first match %+v", two)
+	}
+	if three.Line != 3 || strings.Join(three.Before, ",") != "two" || strings.Join(three.After, ",") != "four" {
+		t.Errorf("Unexpected second match %+v", three)
+	}
+
+	if matches := grepLines("a.md", content, regexp.MustCompile("^o"), 3); len(matches) != 1 || matches[0].Before != nil {
+		t.Errorf("Expected no context before the first line, got %+v", matches)
+	}
+
+	long := strings.Repeat("é", maxGrepLineChars)
+	if got := shortLine(long); !strings.HasSuffix(got, "…") || len(got) > maxGrepLineChars+len("…") {
+		t.Errorf("Expected a long line to be shortened, got %d bytes", len(got))
+	}
+}
+
+func TestGrepEntriesHandler(t *testing.T) {
+	server, client := newTestClient(t)
+	server.AddEntry("docs", "a.md", "Error: disk full\nerror: retry\nok")
+	server.AddEntry("docs", "b.md", "costs 1.5 (approx)\ncosts 125")
+	server.AddEntry("docs", "c.txt", "error in c")
+
+	grep := func(params map[string]interface{}) grepResult {
+		t.Helper()
+		params["collection_name"] = "docs"
+		return callToolJSON[grepResult](t, client, GrepEntriesHandler, params)
+	}
+	lines := func(result grepResult) []string {
+		var out []string
+		for _, m := range result.Matches {
+			out = append(out, m.Entry+":"+m.Text)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   []string
+	}{
+		{
+			name:   "regex",
+			params: map[string]interface{}{"pattern": `1\.5|12`},
+			want:   []string{"b.md:costs 1.5 (approx)", "b.md:costs 125"},
+		},
+		{
+			name:   "literal",
+			params: map[string]interface{}{"pattern": "1.5 (", "literal": true},
+			want:   []string{"b.md:costs 1.5 (approx)"},
+		},
+		{
+			name:   "case sensitive",
+			params: map[string]interface{}{"pattern": "error"},
+			want:   []string{"a.md:error: retry", "c.txt:error in c"},
+		},
+		{
+			name:   "ignore case",
+			params: map[string]interface{}{"pattern": "error", "ignore_case": true},
+			want:   []string{"a.md:Error: disk full", "a.md:error: retry", "c.txt:error in c"},
+		},
+		{
+			name:   "literal ignoring case",
+			params: map[string]interface{}{"pattern": "ERROR:", "literal": true, "ignore_case": true},
+			want:   []string{"a.md:Error: disk full", "a.md:error: retry"},
+		},
+		{
+			name:   "entry pattern",
+			params: map[string]interface{}{"pattern": "error", "entry_pattern": "*.txt"},
+			want:   []string{"c.txt:error in c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := grep(tt.params)
+			if got := lines(result); strings.Join(got, "|") != strings.Join(tt.want, "|") || result.Count != len(tt.want) || result.Truncated {
+				t.Errorf("Expected %v, got %v (truncated %v)", tt.want, got, result.Truncated)
+			}
+		})
+	}
+
+	t.Run("invalid patterns", func(t *testing.T) {
+		for _, params := range []map[string]interface{}{
+			{"collection_name": "docs", "pattern": "(unclosed"},
+			{"collection_name": "docs", "pattern": "x", "entry_pattern": "["},
+			{"collection_name": "docs"},
+		} {
+			if _, err := callTool(client, GrepEntriesHandler, params); err == nil {
+				t.Errorf("Expected %v to be rejected", params)
+			}
+		}
+		// A literal pattern is never an invalid regular expression
+		if result := grep(map[string]interface{}{"pattern": "(unclosed", "literal": true}); result.Count != 0 {
+			t.Errorf("Expected no matches, got %+v", result.Matches)
+		}
+	})
+
+	t.Run("max matches", func(t *testing.T) {
+		result := grep(map[string]interface{}{"pattern": "error", "ignore_case": true, "max_matches": 1})
+		if result.Count != 1 || !result.Truncated || result.EntriesScanned != 1 {
+			t.Errorf("Expected to stop after the first match, got %+v", result)
+		}
+		result = grep(map[string]interface{}{"pattern": "error", "ignore_case": true, "max_matches": 2})
+		if result.Count != 2 || !result.Truncated || result.EntriesScanned != 1 {
+			t.Errorf("Expected unscanned entries to mark the result truncated, got %+v", result)
+		}
+	})
+
+	t.Run("max entries", func(t *testing.T) {
+		result := grep(map[string]interface{}{"pattern": "error", "max_entries": 2})
+		if result.EntriesTotal != 3 || result.EntriesScanned != 2 || !result.Truncated || result.Count != 1 {
+			t.Errorf("Expected only the first 2 entries to be scanned, got %+v", result)
+		}
+	})
+}
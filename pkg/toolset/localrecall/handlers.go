@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	lrclient "github.com/futuretea/localrecall-mcp-server/pkg/client"
+	"github.com/futuretea/localrecall-mcp-server/pkg/core/logging"
 	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
 	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
 )
@@ -19,7 +21,7 @@ func getClient(clientInterface interface{}) (*toolset.LocalRecallClient, error)
 }
 
 // SearchHandler handles search requests
-func SearchHandler(clientInterface interface{}, params map[string]interface{}) (string, error) {
+func SearchHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
 	client, err := getClient(clientInterface)
 	if err != nil {
 		return "", err
@@ -44,16 +46,64 @@ func SearchHandler(clientInterface interface{}, params map[string]interface{}) (
 		}
 	}
 
-	result, err := client.Client.SearchWithOptions(context.Background(), collectionName, query, maxResults, opts)
+	diversify := diversifyOptions{
+		MMR:          handler.GetBoolParam(params, "diversify", false),
+		Lambda:       handler.GetFloat64Param(params, "mmr_lambda", defaultMMRLambda),
+		MaxPerSource: handler.GetIntParam(params, "max_per_source", 0),
+	}
+	if diversify.Lambda < 0 || diversify.Lambda > 1 {
+		return "", fmt.Errorf("mmr_lambda must be between 0 and 1")
+	}
+
+	expand := expandOptions{
+		Mode:         handler.GetStringParam(params, "expand", expandChunk),
+		ContextChars: handler.GetIntParam(params, "context_chars", 0),
+		MaxChars:     handler.GetIntParam(params, "max_context_chars", defaultMaxContextChars),
+	}
+	if expand.Mode != expandChunk && expand.Mode != expandEntry {
+		return "", fmt.Errorf("expand must be one of: %s, %s", expandChunk, expandEntry)
+	}
+
+	resources := handler.GetStringParam(params, "resources", resourcesNone)
+	if resources != resourcesNone && resources != resourcesLink && resources != resourcesEmbedded {
+		return "", fmt.Errorf("resources must be one of: %s, %s, %s", resourcesNone, resourcesLink, resourcesEmbedded)
+	}
+
+	fetchResults := maxResults
+	if diversify.enabled() {
+		fetchResults = overFetch(maxResults)
+	}
+
+	result, err := client.Client.SearchWithOptions(ctx, collectionName, query, fetchResults, opts)
 	if err != nil {
 		return "", fmt.Errorf("search failed: %w", err)
 	}
 
-	return handler.FormatOutput(result, format)
+	if diversify.enabled() {
+		result.Results = diversifyHits(result.Results, maxResults, diversify)
+		result.MaxResults = maxResults
+		result.Count = len(result.Results)
+	}
+
+	if expand.enabled() {
+		expandHits(ctx, newEntryFetcher(client.Client, collectionName), result.Results, expand)
+	}
+
+	maxTokens := handler.GetIntParam(params, "max_tokens", 0)
+	out, err := fitSearchResult(ctx, result, collectionName, format, maxTokens, resources == resourcesEmbedded)
+	if err != nil {
+		return "", err
+	}
+
+	// Point at the entries of the hits that fit the budget
+	if resources != resourcesNone {
+		handler.RecordContent(ctx, hitResources(collectionName, resources, result.Results)...)
+	}
+	return out, nil
 }
 
 // CreateCollectionHandler handles create collection requests
-func CreateCollectionHandler(clientInterface interface{}, params map[string]interface{}) (string, error) {
+func CreateCollectionHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
 	client, err := getClient(clientInterface)
 	if err != nil {
 		return "", err
@@ -66,16 +116,17 @@ func CreateCollectionHandler(clientInterface interface{}, params map[string]inte
 
 	format := handler.GetStringParam(params, "format", "json")
 
-	result, err := client.Client.CreateCollection(context.Background(), name)
+	result, err := client.Client.CreateCollection(ctx, name)
 	if err != nil {
 		return "", fmt.Errorf("create collection failed: %w", err)
 	}
+	client.InvalidateCollection(name)
 
-	return handler.FormatOutput(result, format)
+	return handler.FormatResult(ctx, result, format)
 }
 
 // ResetCollectionHandler handles reset collection requests
-func ResetCollectionHandler(clientInterface interface{}, params map[string]interface{}) (string, error) {
+func ResetCollectionHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
 	client, err := getClient(clientInterface)
 	if err != nil {
 		return "", err
@@ -88,16 +139,27 @@ func ResetCollectionHandler(clientInterface interface{}, params map[string]inter
 
 	format := handler.GetStringParam(params, "format", "json")
 
-	result, err := client.Client.ResetCollection(context.Background(), name)
+	result, err := client.Client.ResetCollection(ctx, name)
 	if err != nil {
 		return "", fmt.Errorf("reset collection failed: %w", err)
 	}
+	client.InvalidateCollection(name)
+	if err := client.Expiry.RemoveCollection(name); err != nil {
+		logging.Warn("Failed to remove expiries of collection %s: %v", name, err)
+	}
 
-	return handler.FormatOutput(result, format)
+	return handler.FormatResult(ctx, result, format)
+}
+
+// addDocumentResult is the response of add_document
+type addDocumentResult struct {
+	lrclient.DocumentInfo `yaml:",inline"`
+	ExpiresAt             string `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+	ArchivedVersion       int    `json:"archived_version,omitempty" yaml:"archived_version,omitempty"`
 }
 
 // AddDocumentHandler handles add document requests
-func AddDocumentHandler(clientInterface interface{}, params map[string]interface{}) (string, error) {
+func AddDocumentHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
 	client, err := getClient(clientInterface)
 	if err != nil {
 		return "", err
@@ -121,6 +183,14 @@ func AddDocumentHandler(clientInterface interface{}, params map[string]interface
 		return "", fmt.Errorf("cannot specify both file_path and file_content")
 	}
 
+	expiresAt, expires, err := parseExpiry(params, time.Now())
+	if err != nil {
+		return "", err
+	}
+	if err := checkExpiryEnabled(client, expires); err != nil {
+		return "", err
+	}
+
 	var fileBytes []byte
 	if filePath != "" {
 		fileBytes, err = os.ReadFile(filePath)
@@ -131,16 +201,33 @@ func AddDocumentHandler(clientInterface interface{}, params map[string]interface
 		fileBytes = []byte(fileContent)
 	}
 
-	result, err := client.Client.AddDocument(context.Background(), collectionName, filename, fileBytes)
+	archived, err := archiveCurrent(ctx, client, collectionName, filename, archiveReasonUpdate)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := client.Client.AddDocument(ctx, collectionName, filename, fileBytes)
 	if err != nil {
 		return "", fmt.Errorf("add document failed: %w", err)
 	}
+	client.InvalidateEntry(collectionName, filename)
+
+	output := &addDocumentResult{DocumentInfo: *result, ArchivedVersion: archived}
+	if expires {
+		if err := scheduleExpiry(client, collectionName, filename, expiresAt); err != nil {
+			return "", err
+		}
+		output.ExpiresAt = expiresAt.UTC().Format(time.RFC3339)
+	} else {
+		// The new content replaces an expiring entry for good
+		forgetExpiry(client, collectionName, filename)
+	}
 
-	return handler.FormatOutput(result, format)
+	return handler.FormatResult(ctx, output, format)
 }
 
 // ListCollectionsHandler handles list collections requests
-func ListCollectionsHandler(clientInterface interface{}, params map[string]interface{}) (string, error) {
+func ListCollectionsHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
 	client, err := getClient(clientInterface)
 	if err != nil {
 		return "", err
@@ -148,16 +235,16 @@ func ListCollectionsHandler(clientInterface interface{}, params map[string]inter
 
 	format := handler.GetStringParam(params, "format", "json")
 
-	result, err := client.Client.ListCollections(context.Background())
+	result, err := client.Client.ListCollections(ctx)
 	if err != nil {
 		return "", fmt.Errorf("list collections failed: %w", err)
 	}
 
-	return handler.FormatOutput(result, format)
+	return handler.FormatResult(ctx, result, format)
 }
 
 // ListFilesHandler handles list files requests
-func ListFilesHandler(clientInterface interface{}, params map[string]interface{}) (string, error) {
+func ListFilesHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
 	client, err := getClient(clientInterface)
 	if err != nil {
 		return "", err
@@ -166,16 +253,16 @@ func ListFilesHandler(clientInterface interface{}, params map[string]interface{}
 	collectionName := handler.GetStringParam(params, "collection_name", "")
 	format := handler.GetStringParam(params, "format", "json")
 
-	result, err := client.Client.ListFiles(context.Background(), collectionName)
+	result, err := client.Client.ListFiles(ctx, collectionName)
 	if err != nil {
 		return "", fmt.Errorf("list files failed: %w", err)
 	}
 
-	return handler.FormatOutput(result, format)
+	return handler.FormatResult(ctx, result, format)
 }
 
 // DeleteEntryHandler handles delete entry requests
-func DeleteEntryHandler(clientInterface interface{}, params map[string]interface{}) (string, error) {
+func DeleteEntryHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
 	client, err := getClient(clientInterface)
 	if err != nil {
 		return "", err
@@ -190,16 +277,18 @@ func DeleteEntryHandler(clientInterface interface{}, params map[string]interface
 
 	format := handler.GetStringParam(params, "format", "json")
 
-	result, err := client.Client.DeleteEntry(context.Background(), collectionName, entry)
+	result, err := client.Client.DeleteEntry(ctx, collectionName, entry)
 	if err != nil {
 		return "", fmt.Errorf("delete entry failed: %w", err)
 	}
+	client.InvalidateEntry(collectionName, entry)
+	forgetExpiry(client, collectionName, entry)
 
-	return handler.FormatOutput(result, format)
+	return handler.FormatResult(ctx, result, format)
 }
 
 // GetEntryContentHandler handles get entry content requests
-func GetEntryContentHandler(clientInterface interface{}, params map[string]interface{}) (string, error) {
+func GetEntryContentHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
 	client, err := getClient(clientInterface)
 	if err != nil {
 		return "", err
@@ -214,16 +303,29 @@ func GetEntryContentHandler(clientInterface interface{}, params map[string]inter
 
 	format := handler.GetStringParam(params, "format", "json")
 
-	result, err := client.Client.GetEntryContent(context.Background(), collectionName, entry)
+	maxTokens := handler.GetIntParam(params, "max_tokens", 0)
+
+	result, err := client.Client.GetEntryContent(ctx, collectionName, entry)
 	if err != nil {
 		return "", fmt.Errorf("get entry content failed: %w", err)
 	}
 
-	return handler.FormatOutput(result, format)
+	// Page through the entry when any paging parameter is given
+	_, hasOffset := params["offset"]
+	_, hasLimit := params["limit"]
+	_, hasUnit := params["unit"]
+	if hasOffset || hasLimit || hasUnit {
+		unit := handler.GetStringParam(params, "unit", unitChars)
+		offset := handler.GetIntParam(params, "offset", 0)
+		limit := handler.GetIntParam(params, "limit", 0)
+		return fitEntryPage(ctx, result, unit, offset, limit, format, maxTokens)
+	}
+
+	return fitEntryContent(ctx, result, format, maxTokens)
 }
 
 // RegisterSourceHandler handles register external source requests
-func RegisterSourceHandler(clientInterface interface{}, params map[string]interface{}) (string, error) {
+func RegisterSourceHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
 	client, err := getClient(clientInterface)
 	if err != nil {
 		return "", err
@@ -239,16 +341,24 @@ func RegisterSourceHandler(clientInterface interface{}, params map[string]interf
 	updateInterval := handler.GetIntParam(params, "update_interval", 0)
 	format := handler.GetStringParam(params, "format", "json")
 
-	result, err := client.Client.RegisterSource(context.Background(), collectionName, sourceURL, updateInterval)
+	result, err := client.Client.RegisterSource(ctx, collectionName, sourceURL, updateInterval)
 	if err != nil {
 		return "", fmt.Errorf("register source failed: %w", err)
 	}
+	client.InvalidateComputed(collectionName)
+
+	return handler.FormatResult(ctx, result, format)
+}
 
-	return handler.FormatOutput(result, format)
+// removeSourceResult is the response of remove_source
+type removeSourceResult struct {
+	Collection string `json:"collection" yaml:"collection"`
+	URL        string `json:"url" yaml:"url"`
+	Removed    bool   `json:"removed" yaml:"removed"`
 }
 
 // RemoveSourceHandler handles remove external source requests
-func RemoveSourceHandler(clientInterface interface{}, params map[string]interface{}) (string, error) {
+func RemoveSourceHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
 	client, err := getClient(clientInterface)
 	if err != nil {
 		return "", err
@@ -261,22 +371,23 @@ func RemoveSourceHandler(clientInterface interface{}, params map[string]interfac
 		return "", err
 	}
 
-	if err := client.Client.RemoveSource(context.Background(), collectionName, sourceURL); err != nil {
+	if err := client.Client.RemoveSource(ctx, collectionName, sourceURL); err != nil {
 		return "", fmt.Errorf("remove source failed: %w", err)
 	}
+	client.InvalidateComputed(collectionName)
 
-	result := map[string]interface{}{
-		"collection": collectionName,
-		"url":        sourceURL,
-		"removed":    true,
+	result := &removeSourceResult{
+		Collection: collectionName,
+		URL:        sourceURL,
+		Removed:    true,
 	}
 
 	format := handler.GetStringParam(params, "format", "json")
-	return handler.FormatOutput(result, format)
+	return handler.FormatResult(ctx, result, format)
 }
 
 // ListSourcesHandler handles list external sources requests
-func ListSourcesHandler(clientInterface interface{}, params map[string]interface{}) (string, error) {
+func ListSourcesHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
 	client, err := getClient(clientInterface)
 	if err != nil {
 		return "", err
@@ -285,10 +396,10 @@ func ListSourcesHandler(clientInterface interface{}, params map[string]interface
 	collectionName := handler.GetStringParam(params, "collection_name", "")
 	format := handler.GetStringParam(params, "format", "json")
 
-	result, err := client.Client.ListSources(context.Background(), collectionName)
+	result, err := client.Client.ListSources(ctx, collectionName)
 	if err != nil {
 		return "", fmt.Errorf("list sources failed: %w", err)
 	}
 
-	return handler.FormatOutput(result, format)
+	return handler.FormatResult(ctx, result, format)
 }
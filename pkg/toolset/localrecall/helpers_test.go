@@ -0,0 +1,39 @@
+package localrecall
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/futuretea/localrecall-mcp-server/internal/lrtest"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+)
+
+// toolHandler is the signature of the tool handlers
+type toolHandler func(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error)
+
+// newTestClient starts a fake LocalRecall server and returns a client for it
+func newTestClient(t *testing.T) (*lrtest.Server, *toolset.LocalRecallClient) {
+	t.Helper()
+	server := lrtest.NewServer(t)
+	return server, &toolset.LocalRecallClient{Client: server.Client()}
+}
+
+// callTool runs a tool handler with JSON output
+func callTool(client *toolset.LocalRecallClient, h toolHandler, params map[string]interface{}) (string, error) {
+	return h(context.Background(), client, params)
+}
+
+// callToolJSON runs a tool handler that must succeed and decodes its JSON output into T
+func callToolJSON[T any](t *testing.T, client *toolset.LocalRecallClient, h toolHandler, params map[string]interface{}) T {
+	t.Helper()
+	out, err := callTool(client, h, params)
+	if err != nil {
+		t.Fatalf("Tool call failed: %v", err)
+	}
+	var result T
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Failed to parse tool output %s: %v", out, err)
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package localrecall
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
+)
+
+const (
+	// resourcesNone returns search hits as text only
+	resourcesNone = "none"
+	// resourcesLink adds a resource link to each entry with hits
+	resourcesLink = "link"
+	// resourcesEmbedded adds each hit as an embedded resource of its entry
+	resourcesEmbedded = "embedded"
+)
+
+// embeddedTokens returns the tokens taken by the text of hits embedded as resources
+func embeddedTokens(hits []map[string]interface{}) int {
+	n := 0
+	for _, hit := range hits {
+		if hitSource(hit) != "" {
+			n += handler.CountTokens(hitText(hit))
+		}
+	}
+	return n
+}
+
+// hitResources returns the search hits as MCP content pointing at their
+// entries. Links are listed once per entry in rank order; embedded resources
+// carry the text of each hit under a chunk URI of its entry, since the text is
+// only part of the entry. Hits without a known source entry are skipped.
+func hitResources(collection, mode string, hits []map[string]interface{}) []mcp.Content {
+	contents := []mcp.Content{}
+	linked := make(map[string]bool)
+	for i, hit := range hits {
+		entry := hitSource(hit)
+		if entry == "" {
+			continue
+		}
+		uri := toolset.EntryURI(collection, entry)
+		score := hitSimilarity(hit)
+		priority := min(max(score, 0), 1)
+		annotations := &mcp.Annotations{Priority: &priority}
+
+		switch mode {
+		case resourcesLink:
+			if linked[entry] {
+				continue
+			}
+			linked[entry] = true
+			link := mcp.NewResourceLink(uri, entry, fmt.Sprintf("Search hit with similarity %.3f", score), toolset.EntryMIMEType(entry))
+			link.Annotations = annotations
+			contents = append(contents, link)
+		case resourcesEmbedded:
+			chunk, _ := hit["ID"].(string)
+			if chunk == "" {
+				chunk = strconv.Itoa(i + 1)
+			}
+			// Expanded context is returned in place of the bare chunk, as in the text
+			resource := mcp.NewEmbeddedResource(mcp.TextResourceContents{
+				URI:      toolset.ChunkURI(collection, entry, chunk),
+				MIMEType: toolset.EntryMIMEType(entry),
+				Text:     hitText(hit),
+			})
+			resource.Annotations = annotations
+			contents = append(contents, resource)
+		}
+	}
+	return contents
+}
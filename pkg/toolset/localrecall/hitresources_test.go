@@ -0,0 +1,118 @@
+package localrecall
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/futuretea/localrecall-mcp-server/internal/lrtest"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
+)
+
+func TestHitResources_Link(t *testing.T) {
+	hits := []map[string]interface{}{
+		lrtest.Hit("b#1", "b one", 1.2, "b.md"),
+		lrtest.Hit("a#1", "a one", 0.8, "a.md"),
+		lrtest.Hit("b#2", "b two", 0.7, "b.md"),
+		{"ID": "x", "Content": "no source", "Similarity": 0.6},
+	}
+
+	contents := hitResources("docs", resourcesLink, hits)
+	if len(contents) != 2 {
+		t.Fatalf("Expected one link per entry, got %d", len(contents))
+	}
+	want := []struct {
+		uri      string
+		priority float64
+	}{
+		{"localrecall://docs/entries/b.md", 1},
+		{"localrecall://docs/entries/a.md", 0.8},
+	}
+	for i, content := range contents {
+		link, ok := content.(mcp.ResourceLink)
+		if !ok {
+			t.Fatalf("Expected a resource link, got %T", content)
+		}
+		if link.URI != want[i].uri || link.MIMEType != "text/markdown" || *link.Annotations.Priority != want[i].priority {
+			t.Errorf("Unexpected link %d: %+v (priority %v)", i, link, *link.Annotations.Priority)
+		}
+	}
+	if desc := contents[1].(mcp.ResourceLink).Description; desc != "Search hit with similarity 0.800" {
+		t.Errorf("Unexpected description %q", desc)
+	}
+}
+
+func TestHitResources_Embedded(t *testing.T) {
+	expanded := lrtest.Hit("a#1", "chunk", 0.9, "a.txt")
+	expanded["Context"] = "before chunk after"
+	hits := []map[string]interface{}{
+		expanded,
+		lrtest.Hit("", "second chunk", -0.1, "a.txt"),
+		{"ID": "x", "Content": "no source", "Similarity": 0.6},
+	}
+
+	contents := hitResources("docs", resourcesEmbedded, hits)
+	if len(contents) != 2 {
+		t.Fatalf("Expected one resource per hit with a source, got %d", len(contents))
+	}
+	want := []struct {
+		uri, text string
+		priority  float64
+	}{
+		{"localrecall://docs/entries/a.txt#chunk=a%231", "before chunk after", 0.9},
+		{"localrecall://docs/entries/a.txt#chunk=2", "second chunk", 0},
+	}
+	for i, content := range contents {
+		resource, ok := content.(mcp.EmbeddedResource)
+		if !ok {
+			t.Fatalf("Expected an embedded resource, got %T", content)
+		}
+		text := resource.Resource.(mcp.TextResourceContents)
+		if text.URI != want[i].uri || text.Text != want[i].text || text.MIMEType != "text/plain" || *resource.Annotations.Priority != want[i].priority {
+			t.Errorf("Unexpected resource %d: %+v (priority %v)", i, text, *resource.Annotations.Priority)
+		}
+	}
+
+	if n := embeddedTokens(hits); n != handler.CountTokens("before chunk after")+handler.CountTokens("second chunk") {
+		t.Errorf("Expected only hits with a source to count, got %d tokens", n)
+	}
+}
+
+func TestSearchHandler_Resources(t *testing.T) {
+	server, client := newTestClient(t)
+	server.AddEntry("docs", "a.md", "alpha beta "+strings.Repeat("filler ", 40))
+	server.AddEntry("docs", "b.md", "alpha "+strings.Repeat("padding ", 40))
+
+	search := func(params map[string]interface{}) []mcp.Content {
+		t.Helper()
+		ctx, recorder := handler.WithResultRecorder(context.Background())
+		params["collection_name"] = "docs"
+		params["query"] = "alpha beta"
+		if _, err := SearchHandler(ctx, client, params); err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		return recorder.Contents()
+	}
+
+	if contents := search(map[string]interface{}{}); len(contents) != 0 {
+		t.Errorf("Expected no resources by default, got %d", len(contents))
+	}
+	if contents := search(map[string]interface{}{"resources": resourcesLink}); len(contents) != 2 {
+		t.Errorf("Expected a link per entry, got %d", len(contents))
+	}
+	if contents := search(map[string]interface{}{"resources": resourcesEmbedded}); len(contents) != 2 {
+		t.Errorf("Expected a resource per hit, got %d", len(contents))
+	}
+
+	// The embedded text counts against the budget, so only the best hit fits
+	contents := search(map[string]interface{}{"resources": resourcesEmbedded, "max_tokens": 150})
+	if len(contents) != 1 || !strings.HasPrefix(contents[0].(mcp.EmbeddedResource).Resource.(mcp.TextResourceContents).Text, "alpha beta") {
+		t.Errorf("Expected only the best hit to be embedded, got %+v", contents)
+	}
+
+	if _, err := callTool(client, SearchHandler, map[string]interface{}{"collection_name": "docs", "query": "alpha", "resources": "inline"}); err == nil {
+		t.Error("Expected an unknown resources mode to be rejected")
+	}
+}
@@ -0,0 +1,206 @@
+package localrecall
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDiffEdits bounds the edit distance explored by the Myers diff. Beyond
+// it the remaining middle section is reported as a full replacement.
+const maxDiffEdits = 2000
+
+// diffOp is a single line of an edit script
+type diffOp struct {
+	kind byte // ' ' unchanged, '-' removed, '+' added
+	text string
+}
+
+// splitLines splits text into lines, ignoring a trailing newline
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines computes a line edit script turning a into b. The second return
+// value is true if the edit limit was hit and the script is not minimal.
+func diffLines(a, b []string) ([]diffOp, bool) {
+	// Common prefix and suffix need no search
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	middle, approximate := myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	ops = append(ops, middle...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops, approximate
+}
+
+// myers implements the O(ND) shortest edit script algorithm
+func myers(a, b []string) ([]diffOp, bool) {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil, false
+	}
+
+	limit := min(n+m, maxDiffEdits)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	// trace[d] holds v[-d..d] as it was before step d
+	var trace [][]int
+
+	for d := 0; d <= limit; d++ {
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b), false
+			}
+		}
+	}
+
+	// Too many differences: replace the whole section
+	ops := make([]diffOp, 0, n+m)
+	for _, line := range a {
+		ops = append(ops, diffOp{'-', line})
+	}
+	for _, line := range b {
+		ops = append(ops, diffOp{'+', line})
+	}
+	return ops, true
+}
+
+// backtrack walks the Myers trace from the end to recover the edit script
+func backtrack(trace [][]int, a, b []string) []diffOp {
+	var ops []diffOp
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{' ', a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			ops = append(ops, diffOp{'+', b[y-1]})
+		} else {
+			ops = append(ops, diffOp{'-', a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		ops = append(ops, diffOp{' ', a[x-1]})
+		x--
+		y--
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// unifiedDiff renders an edit script as a unified diff with the given
+// number of context lines. It returns the diff and the number of hunks.
+func unifiedDiff(fromLabel, toLabel string, ops []diffOp, context int) (string, int) {
+	// Find the changed op indexes and group them into hunks
+	var changes []int
+	for i, op := range ops {
+		if op.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return "", 0
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromLabel, toLabel)
+
+	// Line numbers (1-based) of each op in a and b
+	aLine := make([]int, len(ops)+1)
+	bLine := make([]int, len(ops)+1)
+	aLine[0], bLine[0] = 1, 1
+	for i, op := range ops {
+		aLine[i+1], bLine[i+1] = aLine[i], bLine[i]
+		if op.kind != '+' {
+			aLine[i+1]++
+		}
+		if op.kind != '-' {
+			bLine[i+1]++
+		}
+	}
+
+	hunks := 0
+	for i := 0; i < len(changes); {
+		start := max(changes[i]-context, 0)
+		end := changes[i]
+		j := i
+		for j+1 < len(changes) && changes[j+1]-end <= 2*context+1 {
+			j++
+			end = changes[j]
+		}
+		end = min(end+context+1, len(ops))
+
+		aStart, aCount := aLine[start], aLine[end]-aLine[start]
+		bStart, bCount := bLine[start], bLine[end]-bLine[start]
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+		for _, op := range ops[start:end] {
+			b.WriteByte(op.kind)
+			b.WriteString(op.text)
+			b.WriteByte('\n')
+		}
+		hunks++
+		i = j + 1
+	}
+	return b.String(), hunks
+}
+
+// hunkRange formats a hunk range; empty ranges refer to the line before them
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
@@ -0,0 +1,147 @@
+package localrecall
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// applyOps returns the old and new lines described by an edit script
+func applyOps(ops []diffOp) (from, to []string) {
+	for _, op := range ops {
+		if op.kind != '+' {
+			from = append(from, op.text)
+		}
+		if op.kind != '-' {
+			to = append(to, op.text)
+		}
+	}
+	return from, to
+}
+
+// numberedLines returns the lines "1" to "n" as text, leaving out the lines in skip
+func numberedLines(n int, skip ...int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		if !slices.Contains(skip, i) {
+			fmt.Fprintf(&b, "%d\n", i)
+		}
+	}
+	return b.String()
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  string
+		edits int
+	}{
+		{name: "both empty", a: "", b: "", edits: 0},
+		{name: "empty old", a: "", b: "a\nb\n", edits: 2},
+		{name: "empty new", a: "a\nb\n", b: "", edits: 2},
+		{name: "identical", a: "a\nb\nc\n", b: "a\nb\nc\n", edits: 0},
+		{name: "missing trailing newline", a: "a\nb\n", b: "a\nb", edits: 0},
+		{name: "changed line", a: "a\nb\nc\n", b: "a\nx\nc\n", edits: 2},
+		{name: "shortest edit script", a: "a\nb\nc\na\nb\nb\na\n", b: "c\nb\na\nb\na\nc\n", edits: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := splitLines(tt.a), splitLines(tt.b)
+			ops, approximate := diffLines(a, b)
+			if approximate {
+				t.Error("Expected a minimal diff")
+			}
+			from, to := applyOps(ops)
+			if !slices.Equal(from, a) || !slices.Equal(to, b) {
+				t.Errorf("Expected the edit script to turn %q into %q, got %q and %q", a, b, from, to)
+			}
+			edits := 0
+			for _, op := range ops {
+				if op.kind != ' ' {
+					edits++
+				}
+			}
+			if edits != tt.edits {
+				t.Errorf("Expected %d edits, got %d", tt.edits, edits)
+			}
+		})
+	}
+}
+
+func TestDiffLines_EditLimit(t *testing.T) {
+	a := make([]string, maxDiffEdits/2+1)
+	b := make([]string, len(a))
+	for i := range a {
+		a[i] = fmt.Sprintf("a%d", i)
+		b[i] = fmt.Sprintf("b%d", i)
+	}
+	ops, approximate := diffLines(a, b)
+	if !approximate {
+		t.Error("Expected the edit limit to be hit")
+	}
+	from, to := applyOps(ops)
+	if !slices.Equal(from, a) || !slices.Equal(to, b) {
+		t.Error("Expected the replacement to still turn a into b")
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+		hunks   int
+	}{
+		{
+			name: "identical",
+			a:    "a\nb\n", b: "a\nb\n", context: 3,
+			want: "", hunks: 0,
+		},
+		{
+			name: "empty old",
+			a:    "", b: "a\nb\n", context: 3,
+			want:  "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+			hunks: 1,
+		},
+		{
+			name: "empty new",
+			a:    "a\nb\n", b: "", context: 3,
+			want:  "--- old\n+++ new\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+			hunks: 1,
+		},
+		{
+			name: "single line range",
+			a:    "a\nb\nc\n", b: "a\nx\nc\n", context: 0,
+			want:  "--- old\n+++ new\n@@ -2 +2 @@\n-b\n+x\n",
+			hunks: 1,
+		},
+		{
+			name: "merged at the context boundary",
+			// 6 unchanged lines between the changes are covered by the context of both
+			a: numberedLines(10), b: numberedLines(10, 1, 8), context: 3,
+			want:  "--- old\n+++ new\n@@ -1,10 +1,8 @@\n-1\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n 9\n 10\n",
+			hunks: 1,
+		},
+		{
+			name: "split past the context boundary",
+			// 7 unchanged lines between the changes leave one line out of both contexts
+			a: numberedLines(10), b: numberedLines(10, 1, 9), context: 3,
+			want:  "--- old\n+++ new\n@@ -1,4 +1,3 @@\n-1\n 2\n 3\n 4\n@@ -6,5 +5,4 @@\n 6\n 7\n 8\n-9\n 10\n",
+			hunks: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, _ := diffLines(splitLines(tt.a), splitLines(tt.b))
+			got, hunks := unifiedDiff("old", "new", ops, tt.context)
+			if got != tt.want {
+				t.Errorf("Expected diff\n%s\ngot\n%s", tt.want, got)
+			}
+			if hunks != tt.hunks {
+				t.Errorf("Expected %d hunks, got %d", tt.hunks, hunks)
+			}
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package localrecall
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
+)
+
+const (
+	// memoryPrefix marks entries created by the remember tool
+	memoryPrefix = "memory-"
+	// memoryTimeFormat is the timestamp layout embedded in memory filenames
+	memoryTimeFormat = "20060102T150405.000Z"
+	// maxSlugLen limits the title part of memory filenames
+	maxSlugLen = 40
+	// scoreTieEpsilon is the precision at which scores are compared when ordering memories
+	scoreTieEpsilon = 1e-3
+)
+
+// memoryHeader is the front matter stored at the top of each memory note
+type memoryHeader struct {
+	Title     string   `yaml:"title,omitempty"`
+	Tags      []string `yaml:"tags,omitempty"`
+	CreatedAt string   `yaml:"created_at"`
+}
+
+// memoryNote is a memory note returned by remember and recall
+type memoryNote struct {
+	ID         string   `json:"id" yaml:"id"`
+	Collection string   `json:"collection" yaml:"collection"`
+	Title      string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Tags       []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	CreatedAt  string   `json:"created_at" yaml:"created_at"`
+	ExpiresAt  string   `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+	Score      float64  `json:"score,omitempty" yaml:"score,omitempty"`
+	Content    string   `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+// recallResult is the response of the recall tool
+type recallResult struct {
+	Query    string       `json:"query" yaml:"query"`
+	Memories []memoryNote `json:"memories" yaml:"memories"`
+	Count    int          `json:"count" yaml:"count"`
+}
+
+// memoryFilename builds a unique, time-ordered filename for a memory note
+func memoryFilename(created time.Time, title string) (string, error) {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate memory id: %w", err)
+	}
+	slug := slugify(title)
+	if slug == "" {
+		slug = "note"
+	}
+	return fmt.Sprintf("%s%s-%s-%s.md", memoryPrefix, created.UTC().Format(memoryTimeFormat), hex.EncodeToString(suffix), slug), nil
+}
+
+// memoryCreatedAt parses the creation time embedded in a memory filename
+func memoryCreatedAt(entry string) (time.Time, bool) {
+	rest, ok := strings.CutPrefix(entry, memoryPrefix)
+	if !ok || len(rest) < len(memoryTimeFormat) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(memoryTimeFormat, rest[:len(memoryTimeFormat)])
+	return t, err == nil
+}
+
+// slugify lower-cases text and reduces it to letters, digits and dashes
+func slugify(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= maxSlugLen {
+			break
+		}
+	}
+	return strings.Trim(b.String(), "-")
+}
+
+// renderMemory prepends the front matter header to the note content
+func renderMemory(header memoryHeader, content string) (string, error) {
+	front, err := yaml.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal memory header: %w", err)
+	}
+	return "---\n" + string(front) + "---\n\n" + content, nil
+}
+
+// parseMemory splits a stored note into its header and content. Notes
+// without front matter are returned unchanged with an empty header.
+func parseMemory(text string) (memoryHeader, string) {
+	var header memoryHeader
+	rest, ok := strings.CutPrefix(text, "---\n")
+	if !ok {
+		return header, text
+	}
+	front, body, ok := strings.Cut(rest, "\n---\n")
+	if !ok {
+		return header, text
+	}
+	if err := yaml.Unmarshal([]byte(front), &header); err != nil {
+		return memoryHeader{}, text
+	}
+	return header, strings.TrimLeft(body, "\n")
+}
+
+// RememberHandler stores a memory note under an auto-generated filename
+func RememberHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
+	client, err := getClient(clientInterface)
+	if err != nil {
+		return "", err
+	}
+
+	collectionName := handler.GetStringParam(params, "collection_name", "")
+
+	content, err := handler.RequireStringParam(params, "content")
+	if err != nil {
+		return "", err
+	}
+
+	title := handler.GetStringParam(params, "title", "")
+	tags := handler.GetStringSliceParam(params, "tags")
+	format := handler.GetStringParam(params, "format", "json")
+
+	created := time.Now()
+	expiresAt, expires, err := parseExpiry(params, created)
+	if err != nil {
+		return "", err
+	}
+	if err := checkExpiryEnabled(client, expires); err != nil {
+		return "", err
+	}
+
+	filename, err := memoryFilename(created, title)
+	if err != nil {
+		return "", err
+	}
+
+	header := memoryHeader{
+		Title:     title,
+		Tags:      tags,
+		CreatedAt: created.UTC().Format(time.RFC3339),
+	}
+	text, err := renderMemory(header, content)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := client.Client.AddDocument(ctx, collectionName, filename, []byte(text)); err != nil {
+		return "", fmt.Errorf("remember failed: %w", err)
+	}
+	client.InvalidateEntry(collectionName, filename)
+
+	note := memoryNote{
+		ID:         filename,
+		Collection: collectionName,
+		Title:      title,
+		Tags:       tags,
+		CreatedAt:  header.CreatedAt,
+	}
+	if expires {
+		if err := scheduleExpiry(client, collectionName, filename, expiresAt); err != nil {
+			return "", err
+		}
+		note.ExpiresAt = expiresAt.UTC().Format(time.RFC3339)
+	} else {
+		forgetExpiry(client, collectionName, filename)
+	}
+
+	return handler.FormatResult(ctx, note, format)
+}
+
+// RecallHandler searches memory notes, ordering equal scores newest first
+func RecallHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
+	client, err := getClient(clientInterface)
+	if err != nil {
+		return "", err
+	}
+
+	collectionName := handler.GetStringParam(params, "collection_name", "")
+
+	query, err := handler.RequireStringParam(params, "query")
+	if err != nil {
+		return "", err
+	}
+
+	maxResults := handler.GetIntParam(params, "max_results", 5)
+	if maxResults < 1 {
+		return "", fmt.Errorf("max_results must be positive")
+	}
+	tags := handler.GetStringSliceParam(params, "tags")
+	format := handler.GetStringParam(params, "format", "json")
+
+	result, err := client.Client.Search(ctx, collectionName, query, maxOverFetch)
+	if err != nil {
+		return "", fmt.Errorf("recall failed: %w", err)
+	}
+
+	// Keep the best-scoring chunk of each memory note
+	best := make(map[string]float64)
+	var order []string
+	for _, hit := range result.Results {
+		source := hitSource(hit)
+		if !strings.HasPrefix(source, memoryPrefix) {
+			continue
+		}
+		score, seen := best[source]
+		if !seen {
+			order = append(order, source)
+		}
+		if !seen || hitSimilarity(hit) > score {
+			best[source] = hitSimilarity(hit)
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		si, sj := math.Round(best[order[i]]/scoreTieEpsilon), math.Round(best[order[j]]/scoreTieEpsilon)
+		if si != sj {
+			return si > sj
+		}
+		ti, _ := memoryCreatedAt(order[i])
+		tj, _ := memoryCreatedAt(order[j])
+		return ti.After(tj)
+	})
+
+	// Without a tag filter only the top notes need to be read
+	if len(tags) == 0 && len(order) > maxResults {
+		order = order[:maxResults]
+	}
+
+	recalled := &recallResult{Query: query, Memories: []memoryNote{}}
+	for _, fetched := range fetchEntries(ctx, client, collectionName, order) {
+		if len(recalled.Memories) >= maxResults {
+			break
+		}
+		if fetched.Err != nil {
+			continue
+		}
+		header, body := parseMemory(fetched.Content.Content)
+		if !hasAllTags(header.Tags, tags) {
+			continue
+		}
+		recalled.Memories = append(recalled.Memories, memoryNote{
+			ID:         fetched.Entry,
+			Collection: collectionName,
+			Title:      header.Title,
+			Tags:       header.Tags,
+			CreatedAt:  header.CreatedAt,
+			Score:      best[fetched.Entry],
+			Content:    body,
+		})
+	}
+	recalled.Count = len(recalled.Memories)
+
+	return handler.FormatResult(ctx, recalled, format)
+}
+
+// forgetResult is the response of the forget tool
+type forgetResult struct {
+	Collection string       `json:"collection" yaml:"collection"`
+	Forgotten  []string     `json:"forgotten" yaml:"forgotten"`
+	Count      int          `json:"count" yaml:"count"`
+	Errors     []entryError `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// ForgetHandler deletes a memory note by ID, or every memory note carrying all of the given tags
+func ForgetHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
+	client, err := getClient(clientInterface)
+	if err != nil {
+		return "", err
+	}
+
+	collectionName := handler.GetStringParam(params, "collection_name", "")
+	id := handler.GetStringParam(params, "id", "")
+	tags := handler.GetStringSliceParam(params, "tags")
+	format := handler.GetStringParam(params, "format", "json")
+
+	if (id == "") == (len(tags) == 0) {
+		return "", fmt.Errorf("exactly one of id or tags is required")
+	}
+
+	ids := []string{id}
+	if id != "" && !strings.HasPrefix(id, memoryPrefix) {
+		return "", fmt.Errorf("%s is not a memory id", id)
+	}
+	if len(tags) > 0 {
+		if ids, err = taggedMemories(ctx, client, collectionName, tags); err != nil {
+			return "", err
+		}
+	}
+
+	result := &forgetResult{Collection: collectionName, Forgotten: []string{}}
+	for _, note := range ids {
+		if _, err := client.Client.DeleteEntry(ctx, collectionName, note); err != nil {
+			if id != "" {
+				return "", fmt.Errorf("forget failed: %w", err)
+			}
+			result.Errors = append(result.Errors, entryError{Entry: note, Error: err.Error()})
+			continue
+		}
+		client.InvalidateEntry(collectionName, note)
+		forgetExpiry(client, collectionName, note)
+		result.Forgotten = append(result.Forgotten, note)
+	}
+	result.Count = len(result.Forgotten)
+
+	return handler.FormatResult(ctx, result, format)
+}
+
+// taggedMemories returns the memory notes of a collection carrying all of the given tags
+func taggedMemories(ctx context.Context, client *toolset.LocalRecallClient, collection string, tags []string) ([]string, error) {
+	files, err := client.Client.ListFiles(ctx, collection)
+	if err != nil {
+		return nil, fmt.Errorf("list files failed: %w", err)
+	}
+	var memories []string
+	for _, entry := range files.Entries {
+		if strings.HasPrefix(entry, memoryPrefix) {
+			memories = append(memories, entry)
+		}
+	}
+
+	var tagged []string
+	for fetched := range streamEntries(ctx, client, collection, memories) {
+		if fetched.Err != nil {
+			return nil, fmt.Errorf("failed to read memory note %s: %w", fetched.Entry, fetched.Err)
+		}
+		if header, _ := parseMemory(fetched.Content.Content); hasAllTags(header.Tags, tags) {
+			tagged = append(tagged, fetched.Entry)
+		}
+	}
+	return tagged, nil
+}
+
+// hasAllTags reports whether have contains every tag in want, ignoring case
+func hasAllTags(have, want []string) bool {
+	for _, tag := range want {
+		if !slices.ContainsFunc(have, func(h string) bool { return strings.EqualFold(h, tag) }) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,225 @@
+package localrecall
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/futuretea/localrecall-mcp-server/internal/lrtest"
+)
+
+// addMemory stores a memory note created at the given time in the fake server
+func addMemory(t *testing.T, server *lrtest.Server, collection, created, title string, tags []string, content string) string {
+	t.Helper()
+	at, err := time.Parse(time.RFC3339, created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := memoryPrefix + at.UTC().Format(memoryTimeFormat) + "-000000-" + slugify(title) + ".md"
+	text, err := renderMemory(memoryHeader{Title: title, Tags: tags, CreatedAt: created}, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.AddEntry(collection, id, text)
+	return id
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{in: "Hello, World!", want: "hello-world"},
+		{in: "  --Spaces--  ", want: "spaces"},
+		{in: "Grüße", want: "gr-e"},
+		{in: "", want: ""},
+		{in: strings.Repeat("a", 60), want: strings.Repeat("a", maxSlugLen)},
+	}
+	for _, tt := range tests {
+		if got := slugify(tt.in); got != tt.want {
+			t.Errorf("slugify(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryFilename(t *testing.T) {
+	created := time.Date(2026, 3, 4, 5, 6, 7, 890e6, time.UTC)
+	name, err := memoryFilename(created, "")
+	if err != nil {
+		t.Fatalf("memoryFilename failed: %v", err)
+	}
+	if !strings.HasPrefix(name, "memory-20260304T050607.890Z-") || !strings.HasSuffix(name, "-note.md") {
+		t.Errorf("Unexpected filename %s", name)
+	}
+	if got, ok := memoryCreatedAt(name); !ok || !got.Equal(created) {
+		t.Errorf("Expected creation time %v, got %v (%v)", created, got, ok)
+	}
+	if _, ok := memoryCreatedAt("notes.md"); ok {
+		t.Error("Expected no creation time for a regular entry")
+	}
+}
+
+func TestParseMemory(t *testing.T) {
+	header := memoryHeader{Title: "Title", Tags: []string{"a", "b"}, CreatedAt: "2026-01-01T00:00:00Z"}
+	text, err := renderMemory(header, "the note\n---\nwith a rule")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, body := parseMemory(text)
+	if got.Title != header.Title || !slices.Equal(got.Tags, header.Tags) || body != "the note\n---\nwith a rule" {
+		t.Errorf("Unexpected round trip: %+v %q", got, body)
+	}
+
+	if got, body := parseMemory("plain text"); got.Title != "" || body != "plain text" {
+		t.Errorf("Expected text without front matter unchanged, got %+v %q", got, body)
+	}
+}
+
+func TestRemember(t *testing.T) {
+	server, client := newTestClient(t)
+	server.AddCollection("mem")
+
+	note := callToolJSON[memoryNote](t, client, RememberHandler, map[string]interface{}{
+		"collection_name": "mem",
+		"content":         "Remember the milk",
+		"title":           "Shopping list",
+		"tags":            []interface{}{"todo", "home"},
+	})
+	if !strings.HasPrefix(note.ID, memoryPrefix) || !strings.HasSuffix(note.ID, "-shopping-list.md") {
+		t.Errorf("Unexpected id %s", note.ID)
+	}
+
+	stored, ok := server.Entry("mem", note.ID)
+	if !ok {
+		t.Fatalf("Expected %s to be stored", note.ID)
+	}
+	header, body := parseMemory(stored)
+	if header.Title != "Shopping list" || !slices.Equal(header.Tags, []string{"todo", "home"}) || body != "Remember the milk" {
+		t.Errorf("Unexpected stored note: %+v %q", header, body)
+	}
+}
+
+func TestRecall_Ordering(t *testing.T) {
+	server, client := newTestClient(t)
+	older := addMemory(t, server, "mem", "2026-01-01T00:00:00Z", "older", nil, "coffee beans")
+	newer := addMemory(t, server, "mem", "2026-02-01T00:00:00Z", "newer", nil, "coffee filters")
+	best := addMemory(t, server, "mem", "2025-01-01T00:00:00Z", "best", nil, "coffee coffee")
+	server.AddEntry("mem", "coffee.md", "not a memory note")
+
+	server.Search = func(collection, query string, maxResults int) []map[string]interface{} {
+		return []map[string]interface{}{
+			lrtest.Hit("1", "coffee", 0.9, "coffee.md"),
+			lrtest.Hit("2", "coffee beans", 0.7, older),
+			lrtest.Hit("3", "coffee filters", 0.7, newer),
+			lrtest.Hit("4", "coffee", 0.8, best),
+			// A weaker chunk of an already seen note does not lower its score
+			lrtest.Hit("5", "coffee", 0.1, best),
+		}
+	}
+
+	result := callToolJSON[recallResult](t, client, RecallHandler, map[string]interface{}{
+		"collection_name": "mem",
+		"query":           "coffee",
+	})
+	var ids []string
+	for _, m := range result.Memories {
+		ids = append(ids, m.ID)
+	}
+	if want := []string{best, newer, older}; !slices.Equal(ids, want) {
+		t.Errorf("Expected %v, got %v", want, ids)
+	}
+	if result.Memories[0].Score != 0.8 || result.Memories[0].Content != "coffee coffee" {
+		t.Errorf("Unexpected first memory %+v", result.Memories[0])
+	}
+
+	limited := callToolJSON[recallResult](t, client, RecallHandler, map[string]interface{}{
+		"collection_name": "mem",
+		"query":           "coffee",
+		"max_results":     1,
+	})
+	if limited.Count != 1 || limited.Memories[0].ID != best {
+		t.Errorf("Expected only %s, got %+v", best, limited.Memories)
+	}
+}
+
+func TestRecall_FiltersByTags(t *testing.T) {
+	server, client := newTestClient(t)
+	work := addMemory(t, server, "mem", "2026-01-01T00:00:00Z", "work", []string{"Work", "urgent"}, "deploy on friday")
+	addMemory(t, server, "mem", "2026-01-02T00:00:00Z", "home", []string{"home"}, "deploy the garden hose")
+	addMemory(t, server, "mem", "2026-01-03T00:00:00Z", "maybe", []string{"work"}, "deploy later")
+
+	result := callToolJSON[recallResult](t, client, RecallHandler, map[string]interface{}{
+		"collection_name": "mem",
+		"query":           "deploy",
+		"tags":            []interface{}{"work", "URGENT"},
+	})
+	if result.Count != 1 || result.Memories[0].ID != work {
+		t.Errorf("Expected only %s, got %+v", work, result.Memories)
+	}
+}
+
+func TestRecall_RejectsMaxResults(t *testing.T) {
+	_, client := newTestClient(t)
+	for _, n := range []int{0, -1} {
+		_, err := callTool(client, RecallHandler, map[string]interface{}{
+			"collection_name": "mem",
+			"query":           "anything",
+			"max_results":     n,
+		})
+		if err == nil || !strings.Contains(err.Error(), "max_results") {
+			t.Errorf("Expected a max_results error for %d, got %v", n, err)
+		}
+	}
+}
+
+func TestForget_ByID(t *testing.T) {
+	server, client := newTestClient(t)
+	id := addMemory(t, server, "mem", "2026-01-01T00:00:00Z", "note", nil, "text")
+	server.AddEntry("mem", "regular.md", "text")
+
+	result := callToolJSON[forgetResult](t, client, ForgetHandler, map[string]interface{}{
+		"collection_name": "mem",
+		"id":              id,
+	})
+	if result.Count != 1 || result.Forgotten[0] != id {
+		t.Errorf("Expected %s to be forgotten, got %+v", id, result)
+	}
+	if _, ok := server.Entry("mem", id); ok {
+		t.Error("Expected the note to be deleted")
+	}
+
+	if _, err := callTool(client, ForgetHandler, map[string]interface{}{"collection_name": "mem", "id": "regular.md"}); err == nil {
+		t.Error("Expected forgetting a regular entry to fail")
+	}
+	if _, err := callTool(client, ForgetHandler, map[string]interface{}{"collection_name": "mem", "id": id}); err == nil {
+		t.Error("Expected forgetting a missing note to fail")
+	}
+}
+
+func TestForget_ByTags(t *testing.T) {
+	server, client := newTestClient(t)
+	a := addMemory(t, server, "mem", "2026-01-01T00:00:00Z", "a", []string{"project-x", "done"}, "a")
+	b := addMemory(t, server, "mem", "2026-01-02T00:00:00Z", "b", []string{"Project-X", "done", "extra"}, "b")
+	c := addMemory(t, server, "mem", "2026-01-03T00:00:00Z", "c", []string{"project-x"}, "c")
+
+	result := callToolJSON[forgetResult](t, client, ForgetHandler, map[string]interface{}{
+		"collection_name": "mem",
+		"tags":            []interface{}{"project-x", "done"},
+	})
+	if !slices.Equal(result.Forgotten, []string{a, b}) || result.Count != 2 {
+		t.Errorf("Expected %s and %s to be forgotten, got %+v", a, b, result)
+	}
+	if got := server.Entries("mem"); !slices.Equal(got, []string{c}) {
+		t.Errorf("Expected only %s to remain, got %v", c, got)
+	}
+}
+
+func TestForget_RequiresIDOrTags(t *testing.T) {
+	_, client := newTestClient(t)
+	for _, params := range []map[string]interface{}{
+		{"collection_name": "mem"},
+		{"collection_name": "mem", "id": "memory-x.md", "tags": []interface{}{"a"}},
+	} {
+		if _, err := callTool(client, ForgetHandler, params); err == nil {
+			t.Errorf("Expected %v to be rejected", params)
+		}
+	}
+}
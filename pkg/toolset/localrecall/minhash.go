@@ -0,0 +1,93 @@
+package localrecall
+
+import (
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// minHashSize is the number of hash functions in a MinHash signature
+	minHashSize = 128
+	// shingleSize is the number of consecutive words in a shingle
+	shingleSize = 5
+)
+
+// minHashSignature is a fixed-size sketch of a document's shingle set
+type minHashSignature [minHashSize]uint64
+
+// minHash computes the MinHash signature of text using word shingles.
+// Texts shorter than a shingle are treated as a single shingle.
+func minHash(text string) minHashSignature {
+	var sig minHashSignature
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return sig
+	}
+
+	n := max(len(words)-shingleSize+1, 1)
+	for i := 0; i < n; i++ {
+		end := min(i+shingleSize, len(words))
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:end], " ")))
+		base := h.Sum64()
+		for j := range sig {
+			if v := mix64(base ^ uint64(j)*0x9e3779b97f4a7c15); v < sig[j] {
+				sig[j] = v
+			}
+		}
+	}
+	return sig
+}
+
+// similarity estimates the Jaccard similarity of the shingle sets behind two signatures
+func (s *minHashSignature) similarity(other *minHashSignature) float64 {
+	equal := 0
+	for i := range s {
+		if s[i] == other[i] {
+			equal++
+		}
+	}
+	return float64(equal) / minHashSize
+}
+
+// mix64 is the splitmix64 finalizer, used to derive independent hash functions
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// unionFind groups items into disjoint sets
+type unionFind []int
+
+func newUnionFind(n int) unionFind {
+	uf := make(unionFind, n)
+	for i := range uf {
+		uf[i] = i
+	}
+	return uf
+}
+
+func (uf unionFind) find(i int) int {
+	for uf[i] != i {
+		uf[i] = uf[uf[i]]
+		i = uf[i]
+	}
+	return i
+}
+
+func (uf unionFind) union(a, b int) {
+	if ra, rb := uf.find(a), uf.find(b); ra != rb {
+		uf[rb] = ra
+	}
+}
@@ -0,0 +1,150 @@
+package localrecall
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	lrclient "github.com/futuretea/localrecall-mcp-server/pkg/client"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
+)
+
+const (
+	// unitChars pages entry content by characters
+	unitChars = "chars"
+	// unitLines pages entry content by lines
+	unitLines = "lines"
+)
+
+// entryPage is a window of an entry's content with a continuation cursor
+type entryPage struct {
+	Collection  string        `json:"collection" yaml:"collection"`
+	Entry       string        `json:"entry" yaml:"entry"`
+	Content     string        `json:"content" yaml:"content"`
+	ChunkCount  int           `json:"chunk_count" yaml:"chunk_count"`
+	Unit        string        `json:"unit" yaml:"unit"`
+	Offset      int           `json:"offset" yaml:"offset"`
+	Returned    int           `json:"returned" yaml:"returned"`
+	TotalLength int           `json:"total_length" yaml:"total_length"`
+	HasMore     bool          `json:"has_more" yaml:"has_more"`
+	NextOffset  *int          `json:"next_offset,omitempty" yaml:"next_offset,omitempty"`
+	Budget      *budgetNotice `json:"budget,omitempty" yaml:"budget,omitempty"`
+}
+
+// pagedText addresses content by the units used for paging. Pages are sliced
+// from the content by byte offset, so large entries are not copied per unit.
+type pagedText struct {
+	content string
+	unit    string
+	total   int
+}
+
+// newPagedText counts the units of content
+func newPagedText(content, unit string) pagedText {
+	t := pagedText{content: content, unit: unit}
+	if unit == unitLines {
+		t.total = strings.Count(content, "\n")
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			// A trailing newline does not start another line, but an unterminated last line counts
+			t.total++
+		}
+	} else {
+		t.total = utf8.RuneCountInString(content)
+	}
+	return t
+}
+
+// advance returns the byte offset n units after the byte offset from
+func (t pagedText) advance(from, n int) int {
+	for ; n > 0 && from < len(t.content); n-- {
+		if t.unit == unitLines {
+			i := strings.IndexByte(t.content[from:], '\n')
+			if i < 0 {
+				return len(t.content)
+			}
+			from += i + 1
+		} else {
+			_, size := utf8.DecodeRuneInString(t.content[from:])
+			from += size
+		}
+	}
+	return from
+}
+
+// slice returns the units from start up to end
+func (t pagedText) slice(start, end int) string {
+	from := t.advance(0, start)
+	return t.content[from:t.advance(from, end-start)]
+}
+
+// newEntryPage builds the page of entry starting at offset containing up to
+// limit units. A limit of 0 or less returns the rest of the entry.
+func newEntryPage(entry *lrclient.EntryContent, text pagedText, offset, limit int) *entryPage {
+	total := text.total
+	start := min(offset, total)
+	end := total
+	if limit > 0 && start+limit < total {
+		end = start + limit
+	}
+
+	page := &entryPage{
+		Collection:  entry.Collection,
+		Entry:       entry.Entry,
+		Content:     text.slice(start, end),
+		ChunkCount:  entry.ChunkCount,
+		Unit:        text.unit,
+		Offset:      offset,
+		Returned:    end - start,
+		TotalLength: total,
+		HasMore:     end < total,
+	}
+	if page.HasMore {
+		next := end
+		page.NextOffset = &next
+	}
+	return page
+}
+
+// fitEntryPage renders a page of an entry. When the page exceeds maxTokens it
+// is shortened rather than trimmed, so next_offset stays accurate.
+func fitEntryPage(ctx context.Context, entry *lrclient.EntryContent, unit string, offset, limit int, format string, maxTokens int) (string, error) {
+	if unit != unitChars && unit != unitLines {
+		return "", fmt.Errorf("unit must be one of: %s, %s", unitChars, unitLines)
+	}
+	if offset < 0 {
+		return "", fmt.Errorf("offset must not be negative")
+	}
+	if limit < 0 {
+		return "", fmt.Errorf("limit must not be negative")
+	}
+
+	text := newPagedText(entry.Content, unit)
+	page := newEntryPage(entry, text, offset, limit)
+	out, err := handler.FormatResult(ctx, page, format)
+	if err != nil || maxTokens <= 0 {
+		return out, err
+	}
+
+	var notice *budgetNotice
+	for tokens := handler.CountTokens(out); tokens > maxTokens && page.Returned > 1; tokens = handler.CountTokens(out) {
+		if notice == nil {
+			notice = &budgetNotice{
+				MaxTokens: maxTokens,
+				Hint:      fmt.Sprintf("The page was shortened to fit max_tokens=%d. Continue from next_offset, or request a larger max_tokens.", maxTokens),
+			}
+		}
+		// Shrink the content proportionally to the overshoot, always by at least one unit
+		contentTokens := max(handler.CountTokens(page.Content), 1)
+		shorter := page.Returned * (contentTokens - (tokens - maxTokens)) / contentTokens
+		if shorter >= page.Returned {
+			shorter = page.Returned - 1
+		}
+		page = newEntryPage(entry, text, offset, max(shorter, 1))
+		page.Budget = notice
+		if out, err = handler.FormatResult(ctx, page, format); err != nil {
+			return "", err
+		}
+	}
+	return out, nil
+}
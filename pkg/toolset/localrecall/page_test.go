@@ -0,0 +1,128 @@
+package localrecall
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	lrclient "github.com/futuretea/localrecall-mcp-server/pkg/client"
+)
+
+func TestPagedText(t *testing.T) {
+	tests := []struct {
+		name, content, unit string
+		total               int
+	}{
+		{name: "chars", content: "héllo", unit: unitChars, total: 5},
+		{name: "lines", content: "a\nb\nc\n", unit: unitLines, total: 3},
+		{name: "unterminated line", content: "a\nb", unit: unitLines, total: 2},
+		{name: "empty", content: "", unit: unitLines, total: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newPagedText(tt.content, tt.unit).total; got != tt.total {
+				t.Errorf("Expected %d units, got %d", tt.total, got)
+			}
+		})
+	}
+
+	if got := newPagedText("héllo wörld", unitChars).slice(1, 7); got != "éllo w" {
+		t.Errorf("Expected a slice on character boundaries, got %q", got)
+	}
+	if got := newPagedText("a\nb\nc", unitLines).slice(1, 3); got != "b\nc" {
+		t.Errorf("Expected the last two lines, got %q", got)
+	}
+}
+
+func TestNewEntryPage(t *testing.T) {
+	entry := &lrclient.EntryContent{Collection: "docs", Entry: "a.md", Content: "l1\nl2\nl3\nl4\n", ChunkCount: 2}
+	text := newPagedText(entry.Content, unitLines)
+
+	tests := []struct {
+		name          string
+		offset, limit int
+		content       string
+		returned      int
+		next          int // -1 when there is no next page
+	}{
+		{name: "first page", offset: 0, limit: 2, content: "l1\nl2\n", returned: 2, next: 2},
+		{name: "middle page", offset: 1, limit: 2, content: "l2\nl3\n", returned: 2, next: 3},
+		{name: "last page", offset: 2, limit: 2, content: "l3\nl4\n", returned: 2, next: -1},
+		{name: "limit past the end", offset: 3, limit: 10, content: "l4\n", returned: 1, next: -1},
+		{name: "limit 0 returns the rest", offset: 1, limit: 0, content: "l2\nl3\nl4\n", returned: 3, next: -1},
+		{name: "offset at the end", offset: 4, limit: 2, content: "", returned: 0, next: -1},
+		{name: "offset beyond the end", offset: 10, limit: 2, content: "", returned: 0, next: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := newEntryPage(entry, text, tt.offset, tt.limit)
+			if page.Content != tt.content || page.Returned != tt.returned || page.Offset != tt.offset || page.TotalLength != 4 {
+				t.Errorf("Unexpected page %+v", page)
+			}
+			if tt.next < 0 {
+				if page.HasMore || page.NextOffset != nil {
+					t.Errorf("Expected no next page, got has_more=%v next_offset=%v", page.HasMore, page.NextOffset)
+				}
+			} else if !page.HasMore || page.NextOffset == nil || *page.NextOffset != tt.next {
+				t.Errorf("Expected next_offset %d, got has_more=%v next_offset=%v", tt.next, page.HasMore, page.NextOffset)
+			}
+		})
+	}
+}
+
+func TestFitEntryPage(t *testing.T) {
+	entry := &lrclient.EntryContent{Collection: "docs", Entry: "a.md", Content: strings.Repeat("line of text\n", 100)}
+	page := func(t *testing.T, offset, limit, maxTokens int) entryPage {
+		t.Helper()
+		out, err := fitEntryPage(context.Background(), entry, unitLines, offset, limit, "json", maxTokens)
+		if err != nil {
+			t.Fatalf("fitEntryPage failed: %v", err)
+		}
+		var p entryPage
+		if err := json.Unmarshal([]byte(out), &p); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	t.Run("within budget", func(t *testing.T) {
+		p := page(t, 10, 5, 1000)
+		if p.Returned != 5 || p.Budget != nil || *p.NextOffset != 15 {
+			t.Errorf("Expected the full page, got %+v", p)
+		}
+	})
+
+	t.Run("shortened", func(t *testing.T) {
+		p := page(t, 10, 80, 100)
+		if p.Budget == nil || p.Returned >= 80 || p.Returned < 1 {
+			t.Fatalf("Expected a shortened page with a budget notice, got %d lines (%+v)", p.Returned, p.Budget)
+		}
+		if *p.NextOffset != 10+p.Returned || strings.Count(p.Content, "\n") != p.Returned {
+			t.Errorf("Expected next_offset to follow the returned lines, got %d after %d lines", *p.NextOffset, p.Returned)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, args := range []struct {
+			unit          string
+			offset, limit int
+		}{{"words", 0, 1}, {unitLines, -1, 1}, {unitLines, 0, -1}} {
+			if _, err := fitEntryPage(context.Background(), entry, args.unit, args.offset, args.limit, "json", 0); err == nil {
+				t.Errorf("Expected %+v to be rejected", args)
+			}
+		}
+	})
+}
+
+func TestGetEntryContentHandler_Paging(t *testing.T) {
+	server, client := newTestClient(t)
+	server.AddEntry("docs", "a.md", "héllo wörld")
+
+	p := callToolJSON[entryPage](t, client, GetEntryContentHandler, map[string]interface{}{
+		"collection_name": "docs", "entry": "a.md", "offset": 6, "limit": 3,
+	})
+	if p.Unit != unitChars || p.Content != "wör" || *p.NextOffset != 9 || p.TotalLength != 11 {
+		t.Errorf("Expected a character page, got %+v", p)
+	}
+}
@@ -0,0 +1,45 @@
+package localrecall
+
+// Search hits are returned by LocalRecall as loosely typed maps. The helpers
+// below give the post-processing steps typed access to the fields they need.
+
+// hitContent returns the chunk text of a search hit
+func hitContent(hit map[string]interface{}) string {
+	if s, ok := hit["Content"].(string); ok {
+		return s
+	}
+	return ""
+}
+
+// hitText returns the text a search hit stands for: its expanded context if
+// present, otherwise its chunk
+func hitText(hit map[string]interface{}) string {
+	if s, ok := hit["Context"].(string); ok && s != "" {
+		return s
+	}
+	return hitContent(hit)
+}
+
+// hitSimilarity returns the similarity score of a search hit
+func hitSimilarity(hit map[string]interface{}) float64 {
+	switch v := hit["Similarity"].(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	}
+	return 0
+}
+
+// hitSource returns the entry a search hit was chunked from, or "" if unknown
+func hitSource(hit map[string]interface{}) string {
+	switch md := hit["Metadata"].(type) {
+	case map[string]interface{}:
+		if s, ok := md["source"].(string); ok {
+			return s
+		}
+	case map[string]string:
+		return md["source"]
+	}
+	return ""
+}
@@ -0,0 +1,158 @@
+package localrecall
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
+)
+
+const (
+	// defaultStatsTop is the number of largest entries reported
+	defaultStatsTop = 5
+	// noExtension labels entries without a file extension
+	noExtension = "(none)"
+)
+
+// collectionStats is the response of the collection_stats tool
+type collectionStats struct {
+	Collection     string                   `json:"collection" yaml:"collection"`
+	EntryCount     int                      `json:"entry_count" yaml:"entry_count"`
+	TotalBytes     int                      `json:"total_bytes" yaml:"total_bytes"`
+	AverageBytes   int                      `json:"average_bytes" yaml:"average_bytes"`
+	TotalChunks    int                      `json:"total_chunks" yaml:"total_chunks"`
+	LargestEntries []entrySize              `json:"largest_entries" yaml:"largest_entries"`
+	Extensions     []extensionStats         `json:"extensions" yaml:"extensions"`
+	SourceCount    int                      `json:"source_count" yaml:"source_count"`
+	Sources        []map[string]interface{} `json:"sources" yaml:"sources"`
+	Errors         []entryError             `json:"errors,omitempty" yaml:"errors,omitempty"`
+	ComputedAt     string                   `json:"computed_at" yaml:"computed_at"`
+	Cached         bool                     `json:"cached" yaml:"cached"`
+}
+
+// entrySize describes the size of a single entry
+type entrySize struct {
+	Entry  string `json:"entry" yaml:"entry"`
+	Bytes  int    `json:"bytes" yaml:"bytes"`
+	Chunks int    `json:"chunks" yaml:"chunks"`
+}
+
+// extensionStats aggregates entries sharing a file extension
+type extensionStats struct {
+	Extension string `json:"extension" yaml:"extension"`
+	Count     int    `json:"count" yaml:"count"`
+	Bytes     int    `json:"bytes" yaml:"bytes"`
+}
+
+// CollectionStatsHandler handles collection statistics requests
+func CollectionStatsHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
+	client, err := getClient(clientInterface)
+	if err != nil {
+		return "", err
+	}
+
+	collectionName := handler.GetStringParam(params, "collection_name", "")
+	top := max(handler.GetIntParam(params, "top", defaultStatsTop), 0)
+	refresh := handler.GetBoolParam(params, "refresh", false)
+	format := handler.GetStringParam(params, "format", "json")
+
+	key := toolset.ComputedKey(collectionName, "stats")
+	if !refresh {
+		if cached, ok := client.Computed.Get(key); ok {
+			if stats, ok := cached.(collectionStats); ok {
+				stats.Cached = true
+				stats.LargestEntries = stats.LargestEntries[:min(top, len(stats.LargestEntries))]
+				return handler.FormatResult(ctx, stats, format)
+			}
+		}
+	}
+
+	stats, err := computeCollectionStats(ctx, client, collectionName)
+	if err != nil {
+		return "", err
+	}
+	client.Computed.Set(key, *stats)
+
+	stats.LargestEntries = stats.LargestEntries[:min(top, len(stats.LargestEntries))]
+	return handler.FormatResult(ctx, stats, format)
+}
+
+// computeCollectionStats fetches every entry of a collection and aggregates
+// their sizes. All entries are kept in LargestEntries, sorted by size, so
+// cached results can serve any top value.
+func computeCollectionStats(ctx context.Context, client *toolset.LocalRecallClient, collection string) (*collectionStats, error) {
+	files, err := client.Client.ListFiles(ctx, collection)
+	if err != nil {
+		return nil, fmt.Errorf("list files failed: %w", err)
+	}
+	sources, err := client.Client.ListSources(ctx, collection)
+	if err != nil {
+		return nil, fmt.Errorf("list sources failed: %w", err)
+	}
+
+	stats := &collectionStats{
+		Collection:     collection,
+		EntryCount:     len(files.Entries),
+		LargestEntries: []entrySize{},
+		Extensions:     []extensionStats{},
+		SourceCount:    sources.Count,
+		Sources:        sources.Sources,
+		ComputedAt:     time.Now().UTC().Format(time.RFC3339),
+	}
+	if stats.Sources == nil {
+		stats.Sources = []map[string]interface{}{}
+	}
+
+	extensions := make(map[string]*extensionStats)
+	for _, fetched := range fetchEntries(ctx, client, collection, files.Entries) {
+		ext := strings.ToLower(path.Ext(fetched.Entry))
+		if ext == "" {
+			ext = noExtension
+		}
+		es, ok := extensions[ext]
+		if !ok {
+			es = &extensionStats{Extension: ext}
+			extensions[ext] = es
+		}
+		es.Count++
+
+		if fetched.Err != nil {
+			stats.Errors = append(stats.Errors, entryError{Entry: fetched.Entry, Error: fetched.Err.Error()})
+			continue
+		}
+
+		size := entrySize{
+			Entry:  fetched.Entry,
+			Bytes:  len(fetched.Content.Content),
+			Chunks: fetched.Content.ChunkCount,
+		}
+		es.Bytes += size.Bytes
+		stats.TotalBytes += size.Bytes
+		stats.TotalChunks += size.Chunks
+		stats.LargestEntries = append(stats.LargestEntries, size)
+	}
+
+	if n := len(stats.LargestEntries); n > 0 {
+		stats.AverageBytes = stats.TotalBytes / n
+	}
+	sort.SliceStable(stats.LargestEntries, func(i, j int) bool {
+		return stats.LargestEntries[i].Bytes > stats.LargestEntries[j].Bytes
+	})
+
+	for _, es := range extensions {
+		stats.Extensions = append(stats.Extensions, *es)
+	}
+	sort.Slice(stats.Extensions, func(i, j int) bool {
+		if stats.Extensions[i].Count != stats.Extensions[j].Count {
+			return stats.Extensions[i].Count > stats.Extensions[j].Count
+		}
+		return stats.Extensions[i].Extension < stats.Extensions[j].Extension
+	})
+
+	return stats, nil
+}
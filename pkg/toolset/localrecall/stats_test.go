@@ -0,0 +1,79 @@
+package localrecall
+
+import (
+	"testing"
+	"time"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+)
+
+func TestCollectionStatsHandler(t *testing.T) {
+	server, client := newTestClient(t)
+	server.AddEntry("docs", "big.md", "0123456789")
+	server.AddEntry("docs", "small.MD", "0123")
+	server.AddEntry("docs", "notes.txt", "012345")
+	server.AddEntry("docs", "README", "01")
+	server.AddSource("docs", "https://example.com/feed", 60)
+
+	stats := callToolJSON[collectionStats](t, client, CollectionStatsHandler, map[string]interface{}{"collection_name": "docs", "top": 2})
+	if stats.EntryCount != 4 || stats.TotalBytes != 22 || stats.AverageBytes != 5 || stats.SourceCount != 1 || stats.Cached {
+		t.Errorf("Unexpected aggregates %+v", stats)
+	}
+	if len(stats.LargestEntries) != 2 || stats.LargestEntries[0].Entry != "big.md" || stats.LargestEntries[1].Entry != "notes.txt" {
+		t.Errorf("Expected the 2 largest entries, got %+v", stats.LargestEntries)
+	}
+	want := []extensionStats{{".md", 2, 14}, {"(none)", 1, 2}, {".txt", 1, 6}}
+	if len(stats.Extensions) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, stats.Extensions)
+	}
+	for i := range want {
+		if stats.Extensions[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, stats.Extensions)
+			break
+		}
+	}
+
+	server.FailRead = func(_, entry string) bool { return entry == "README" }
+	stats = callToolJSON[collectionStats](t, client, CollectionStatsHandler, map[string]interface{}{"collection_name": "docs"})
+	if stats.TotalBytes != 20 || len(stats.Errors) != 1 || stats.Errors[0].Entry != "README" || stats.AverageBytes != 6 {
+		t.Errorf("Expected unreadable entries to be reported and left out of the sizes, got %+v", stats)
+	}
+}
+
+func TestCollectionStatsHandler_Cache(t *testing.T) {
+	server, client := newTestClient(t)
+	server.AddEntry("docs", "a.md", "aaaa")
+	client.Computed = toolset.NewCache[any](100 * time.Millisecond)
+	params := map[string]interface{}{"collection_name": "docs"}
+	listed := func() int { return server.Requests("GET /api/collections/docs/entries") }
+
+	first := callToolJSON[collectionStats](t, client, CollectionStatsHandler, params)
+	server.AddEntry("docs", "b.md", "bb")
+
+	cached := callToolJSON[collectionStats](t, client, CollectionStatsHandler, map[string]interface{}{"collection_name": "docs", "top": 0})
+	if !cached.Cached || cached.EntryCount != 1 || cached.ComputedAt != first.ComputedAt || listed() != 1 {
+		t.Errorf("Expected the cached statistics, got %+v after %d listings", cached, listed())
+	}
+	if len(cached.LargestEntries) != 0 {
+		t.Errorf("Expected top to apply to cached statistics, got %+v", cached.LargestEntries)
+	}
+
+	refreshed := callToolJSON[collectionStats](t, client, CollectionStatsHandler, map[string]interface{}{"collection_name": "docs", "refresh": true})
+	if refreshed.Cached || refreshed.EntryCount != 2 {
+		t.Errorf("Expected refresh to recompute, got %+v", refreshed)
+	}
+
+	server.AddEntry("docs", "c.md", "c")
+	time.Sleep(150 * time.Millisecond)
+	expired := callToolJSON[collectionStats](t, client, CollectionStatsHandler, params)
+	if expired.Cached || expired.EntryCount != 3 {
+		t.Errorf("Expected the statistics to be recomputed after the TTL, got %+v", expired)
+	}
+
+	client.Computed = nil
+	for range 2 {
+		if stats := callToolJSON[collectionStats](t, client, CollectionStatsHandler, params); stats.Cached {
+			t.Error("Expected no caching without a cache")
+		}
+	}
+}
@@ -1,10 +1,13 @@
 package localrecall
 
 import (
+	"encoding/json"
+	"fmt"
 	"maps"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
+	lrclient "github.com/futuretea/localrecall-mcp-server/pkg/client"
 	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
 )
 
@@ -39,7 +42,67 @@ type collectionToolDef struct {
 	descGeneric string // description when no default collection
 	handler     toolset.ToolHandler
 	props       map[string]interface{} // properties excluding collection_name
+	crossProps  map[string]interface{} // properties naming another collection, hidden under isolation
+	crossOnly   bool                   // tool needs a second collection and is omitted under isolation
 	required    []string               // required params excluding collection_name
+	annotations mcp.ToolAnnotation     // behaviour hints for clients
+	output      mcp.ToolOption         // declares the output schema of the structured result
+	confirm     toolset.ConfirmFunc    // describes destructive calls for the user to confirm
+}
+
+// readOnly returns the annotations of a tool that only reads from LocalRecall
+func readOnly(title string) mcp.ToolAnnotation {
+	return mcp.ToolAnnotation{
+		Title:           title,
+		ReadOnlyHint:    mcp.ToBoolPtr(true),
+		DestructiveHint: mcp.ToBoolPtr(false),
+		IdempotentHint:  mcp.ToBoolPtr(true),
+		OpenWorldHint:   mcp.ToBoolPtr(false),
+	}
+}
+
+// mutating returns the annotations of a tool that changes LocalRecall.
+// destructive marks tools that can delete or overwrite existing content;
+// idempotent marks tools whose repeated calls with the same arguments have no
+// further effect.
+func mutating(title string, destructive, idempotent bool) mcp.ToolAnnotation {
+	return mcp.ToolAnnotation{
+		Title:           title,
+		ReadOnlyHint:    mcp.ToBoolPtr(false),
+		DestructiveHint: mcp.ToBoolPtr(destructive),
+		IdempotentHint:  mcp.ToBoolPtr(idempotent),
+		OpenWorldHint:   mcp.ToBoolPtr(false),
+	}
+}
+
+// openWorld marks a tool that makes LocalRecall reach external systems
+func openWorld(annotations mcp.ToolAnnotation) mcp.ToolAnnotation {
+	annotations.OpenWorldHint = mcp.ToBoolPtr(true)
+	return annotations
+}
+
+// withOutput sets the output schema of a tool
+func withOutput(tool mcp.Tool, output mcp.ToolOption) mcp.Tool {
+	output(&tool)
+	return tool
+}
+
+// outputSchemaAnyOf declares an output schema matching any of the given
+// schemas, for tools whose result shape depends on their parameters
+func outputSchemaAnyOf(outputs ...mcp.ToolOption) mcp.ToolOption {
+	schemas := make([]mcp.ToolOutputSchema, 0, len(outputs))
+	for _, output := range outputs {
+		schemas = append(schemas, withOutput(mcp.Tool{}, output).OutputSchema)
+	}
+	raw, err := json.Marshal(map[string]interface{}{
+		"type":  "object",
+		"anyOf": schemas,
+	})
+	if err != nil {
+		// The schemas were generated from Go types and always marshal
+		panic(fmt.Sprintf("failed to marshal output schema: %v", err))
+	}
+	return mcp.WithRawOutputSchema(raw)
 }
 
 // buildCollectionTool creates a ServerTool from a collectionToolDef,
@@ -58,11 +121,12 @@ func (t *Toolset) buildCollectionTool(def collectionToolDef) toolset.ServerTool
 	} else {
 		desc = def.descGeneric
 		props["collection_name"] = prop("string", "The name of the collection")
+		maps.Copy(props, def.crossProps)
 		required = append([]string{"collection_name"}, required...)
 	}
 
 	return toolset.ServerTool{
-		Tool: mcp.Tool{
+		Tool: withOutput(mcp.Tool{
 			Name:        def.name,
 			Description: desc,
 			InputSchema: mcp.ToolInputSchema{
@@ -70,8 +134,10 @@ func (t *Toolset) buildCollectionTool(def collectionToolDef) toolset.ServerTool
 				Properties: props,
 				Required:   required,
 			},
-		},
+			Annotations: def.annotations,
+		}, def.output),
 		Handler: def.handler,
+		Confirm: def.confirm,
 	}
 }
 
@@ -84,6 +150,8 @@ func (t *Toolset) GetTools(client interface{}) []toolset.ServerTool {
 			descDefault: "Search content in LocalRecall collection",
 			descGeneric: "Search content in a LocalRecall collection",
 			handler:     SearchHandler,
+			annotations: readOnly("Search Collection"),
+			output:      mcp.WithOutputSchema[budgetedSearchResult](),
 			props: map[string]interface{}{
 				"query":          prop("string", "The search query"),
 				"max_results":    prop("number", "Maximum number of results to return (default: 5)"),
@@ -95,18 +163,64 @@ func (t *Toolset) GetTools(client interface{}) []toolset.ServerTool {
 						"type": "string",
 					},
 				},
+				"diversify":      prop("boolean", "Re-rank results with Maximal Marginal Relevance so near-identical chunks are not returned together (default: false)"),
+				"mmr_lambda":     prop("number", "Relevance/diversity trade-off used by diversify (0-1, higher favours relevance; default: 0.5)"),
+				"max_per_source": prop("number", "Maximum number of results from the same source entry (0 or omit for no limit)"),
+				"context_chars":  prop("number", "Characters of surrounding entry text to return before and after each matched chunk (0 or omit to disable)"),
+				"expand": map[string]interface{}{
+					"type":        "string",
+					"description": "Expansion mode: 'chunk' returns the chunk with context_chars of surrounding text, 'entry' returns the full parent entry once per source (default: chunk)",
+					"enum":        []string{"chunk", "entry"},
+				},
+				"max_context_chars": prop("number", "Total budget in characters for expanded context across all results (default: 20000)"),
+				"format": map[string]interface{}{
+					"type":        "string",
+					"description": "Output format: 'markdown' groups results by source entry with numbered citations and highlighted query terms",
+					"enum":        []string{"json", "yaml", "markdown"},
+				},
+				"max_tokens": prop("number", "Approximate token budget for the response. Lowest-scoring results are dropped and long contents trimmed to fit (0 or omit for the server default)"),
+				"resources": map[string]interface{}{
+					"type":        "string",
+					"description": "Also return the hits as MCP resources of their entries: 'link' adds a resource link per source entry, 'embedded' adds each hit's text as an embedded resource (default: none)",
+					"enum":        []string{"none", "link", "embedded"},
+				},
 			},
 			required: []string{"query"},
 		},
+		{
+			name:        "ask",
+			descDefault: "Answer a question from LocalRecall collection, citing the entries used",
+			descGeneric: "Answer a question from a LocalRecall collection, citing the entries used",
+			handler:     AskHandler,
+			annotations: readOnly("Ask Collection"),
+			output:      mcp.WithOutputSchema[askResult](),
+			props: map[string]interface{}{
+				"question":          prop("string", "The question to answer"),
+				"max_results":       prop("number", "Maximum number of search results to answer from (default: 5)"),
+				"min_similarity":    prop("number", "Minimum cosine similarity threshold (0-1) for the results used. 0 or omit to disable."),
+				"answer_max_tokens": prop("number", "Maximum number of tokens the client's model may generate for the answer (default: 1024)"),
+				"format": map[string]interface{}{
+					"type":        "string",
+					"description": "Output format: 'markdown' renders the answer followed by the numbered sources",
+					"enum":        []string{"json", "yaml", "markdown"},
+				},
+			},
+			required: []string{"question"},
+		},
 		{
 			name:        "add_document",
 			descDefault: "Add a document to LocalRecall collection",
 			descGeneric: "Add a document to a LocalRecall collection",
 			handler:     AddDocumentHandler,
+			confirm:     describer(confirmAddDocument),
+			annotations: mutating("Add Document", true, true),
+			output:      mcp.WithOutputSchema[addDocumentResult](),
 			props: map[string]interface{}{
 				"filename":     prop("string", "The filename for the document"),
 				"file_path":    prop("string", "Path to the file to upload (mutually exclusive with file_content)"),
 				"file_content": prop("string", "File content as string (mutually exclusive with file_path)"),
+				"ttl":          prop("string", "Delete the entry automatically after this long, as seconds or a duration such as '90m', '24h' or '7d' (mutually exclusive with expires_at)"),
+				"expires_at":   prop("string", "Delete the entry automatically at this RFC 3339 time (mutually exclusive with ttl)"),
 			},
 			required: []string{"filename"},
 		},
@@ -115,12 +229,17 @@ func (t *Toolset) GetTools(client interface{}) []toolset.ServerTool {
 			descDefault: "List files in LocalRecall collection",
 			descGeneric: "List files in a LocalRecall collection",
 			handler:     ListFilesHandler,
+			annotations: readOnly("List Files"),
+			output:      mcp.WithOutputSchema[lrclient.FilesList](),
 		},
 		{
 			name:        "delete_entry",
 			descDefault: "Delete an entry from LocalRecall collection",
 			descGeneric: "Delete an entry from a LocalRecall collection",
 			handler:     DeleteEntryHandler,
+			confirm:     describer(confirmDeleteEntry),
+			annotations: mutating("Delete Entry", true, true),
+			output:      mcp.WithOutputSchema[lrclient.DeleteResult](),
 			props: map[string]interface{}{
 				"entry": prop("string", "The filename of the entry to delete"),
 			},
@@ -131,16 +250,261 @@ func (t *Toolset) GetTools(client interface{}) []toolset.ServerTool {
 			descDefault: "Get the content of a specific entry in LocalRecall collection",
 			descGeneric: "Get the content of a specific entry in a LocalRecall collection",
 			handler:     GetEntryContentHandler,
+			annotations: readOnly("Get Entry Content"),
+			output:      outputSchemaAnyOf(mcp.WithOutputSchema[budgetedEntryContent](), mcp.WithOutputSchema[entryPage]()),
+			props: map[string]interface{}{
+				"entry":      prop("string", "The filename of the entry to retrieve"),
+				"max_tokens": prop("number", "Approximate token budget for the response. Content beyond the budget is trimmed with a marker, or the page shortened when paging (0 or omit for the server default)"),
+				"offset":     prop("number", "Start position for paged reading, in units (default: 0). Use next_offset from the previous page to continue"),
+				"limit":      prop("number", "Maximum number of units to return when paging (0 or omit for the rest of the entry)"),
+				"unit": map[string]interface{}{
+					"type":        "string",
+					"description": "Unit for offset and limit (default: chars)",
+					"enum":        []string{"chars", "lines"},
+				},
+			},
+			required: []string{"entry"},
+		},
+		{
+			name:        "grep_entries",
+			descDefault: "Find lines matching a regular expression or literal text across entries in LocalRecall collection",
+			descGeneric: "Find lines matching a regular expression or literal text across entries in a LocalRecall collection",
+			handler:     GrepEntriesHandler,
+			annotations: readOnly("Grep Entries"),
+			output:      mcp.WithOutputSchema[grepResult](),
+			props: map[string]interface{}{
+				"pattern":       prop("string", "Regular expression (RE2 syntax) or literal text to search for"),
+				"literal":       prop("boolean", "Treat pattern as literal text instead of a regular expression (default: false)"),
+				"ignore_case":   prop("boolean", "Match case-insensitively (default: false)"),
+				"context_lines": prop("number", "Number of lines of context to return before and after each match (default: 0)"),
+				"entry_pattern": prop("string", "Glob pattern restricting which entries are scanned, e.g. '*.md'"),
+				"max_entries":   prop("number", "Maximum number of entries to scan (default: 100)"),
+				"max_matches":   prop("number", "Maximum number of matching lines to return (default: 50)"),
+			},
+			required: []string{"pattern"},
+		},
+		{
+			name:        "collection_stats",
+			descDefault: "Report statistics for LocalRecall collection",
+			descGeneric: "Report statistics for a LocalRecall collection",
+			handler:     CollectionStatsHandler,
+			annotations: readOnly("Collection Statistics"),
+			output:      mcp.WithOutputSchema[collectionStats](),
+			props: map[string]interface{}{
+				"top":     prop("number", "Number of largest entries to report (default: 5)"),
+				"refresh": prop("boolean", "Recompute statistics instead of using cached results (default: false)"),
+			},
+		},
+		{
+			name:        "find_duplicates",
+			descDefault: "Find near-duplicate entries in LocalRecall collection",
+			descGeneric: "Find near-duplicate entries in a LocalRecall collection",
+			handler:     FindDuplicatesHandler,
+			confirm:     describer(confirmFindDuplicates),
+			annotations: mutating("Find Duplicates", true, false),
+			output:      mcp.WithOutputSchema[duplicatesResult](),
+			props: map[string]interface{}{
+				"threshold":     prop("number", "Minimum estimated content similarity (0-1) for entries to be grouped as duplicates (default: 0.8)"),
+				"max_entries":   prop("number", "Maximum number of entries to compare (default: 500)"),
+				"delete_extras": prop("boolean", "Delete every entry in a group except the suggested keeper (default: false)"),
+				"dry_run":       prop("boolean", "With delete_extras, only report what would be deleted (default: true)"),
+			},
+		},
+		{
+			name:        "remember",
+			descDefault: "Store a memory note in LocalRecall collection",
+			descGeneric: "Store a memory note in a LocalRecall collection",
+			handler:     RememberHandler,
+			annotations: mutating("Remember Note", false, false),
+			output:      mcp.WithOutputSchema[memoryNote](),
+			props: map[string]interface{}{
+				"content": prop("string", "The text to remember"),
+				"title":   prop("string", "Optional short title for the note"),
+				"tags": map[string]interface{}{
+					"type":        "array",
+					"description": "Optional tags for filtering in recall",
+					"items":       map[string]interface{}{"type": "string"},
+				},
+				"ttl":        prop("string", "Forget the note automatically after this long, as seconds or a duration such as '24h' or '7d' (mutually exclusive with expires_at)"),
+				"expires_at": prop("string", "Forget the note automatically at this RFC 3339 time (mutually exclusive with ttl)"),
+			},
+			required: []string{"content"},
+		},
+		{
+			name:        "recall",
+			descDefault: "Search memory notes in LocalRecall collection",
+			descGeneric: "Search memory notes in a LocalRecall collection",
+			handler:     RecallHandler,
+			annotations: readOnly("Recall Notes"),
+			output:      mcp.WithOutputSchema[recallResult](),
+			props: map[string]interface{}{
+				"query":       prop("string", "What to recall"),
+				"max_results": prop("number", "Maximum number of notes to return (default: 5)"),
+				"tags": map[string]interface{}{
+					"type":        "array",
+					"description": "Only return notes carrying all of these tags",
+					"items":       map[string]interface{}{"type": "string"},
+				},
+			},
+			required: []string{"query"},
+		},
+		{
+			name:        "forget",
+			descDefault: "Delete memory notes from LocalRecall collection by id or by tags",
+			descGeneric: "Delete memory notes from a LocalRecall collection by id or by tags",
+			handler:     ForgetHandler,
+			confirm:     describer(confirmForget),
+			annotations: mutating("Forget Note", true, true),
+			output:      mcp.WithOutputSchema[forgetResult](),
+			props: map[string]interface{}{
+				"id": prop("string", "The id of the memory note, as returned by remember or recall (mutually exclusive with tags)"),
+				"tags": map[string]interface{}{
+					"type":        "array",
+					"description": "Delete every memory note carrying all of these tags (mutually exclusive with id)",
+					"items":       map[string]interface{}{"type": "string"},
+				},
+			},
+		},
+		{
+			name:        "list_entry_versions",
+			descDefault: "List the archived versions of an entry in LocalRecall collection",
+			descGeneric: "List the archived versions of an entry in a LocalRecall collection",
+			handler:     ListEntryVersionsHandler,
+			annotations: readOnly("List Entry Versions"),
+			output:      mcp.WithOutputSchema[entryVersionsResult](),
+			props: map[string]interface{}{
+				"entry": prop("string", "The entry to list versions of"),
+			},
+			required: []string{"entry"},
+		},
+		{
+			name:        "get_entry_version",
+			descDefault: "Get the content of an archived version of an entry in LocalRecall collection",
+			descGeneric: "Get the content of an archived version of an entry in a LocalRecall collection",
+			handler:     GetEntryVersionHandler,
+			annotations: readOnly("Get Entry Version"),
+			output:      mcp.WithOutputSchema[entryVersion](),
+			props: map[string]interface{}{
+				"entry":   prop("string", "The entry to read"),
+				"version": prop("number", "The version number, as returned by list_entry_versions"),
+			},
+			required: []string{"entry", "version"},
+		},
+		{
+			name:        "restore_entry_version",
+			descDefault: "Restore an entry in LocalRecall collection to an archived version",
+			descGeneric: "Restore an entry in a LocalRecall collection to an archived version",
+			handler:     RestoreEntryVersionHandler,
+			confirm:     describer(confirmRestoreEntryVersion),
+			annotations: mutating("Restore Entry Version", true, true),
+			output:      mcp.WithOutputSchema[restoreResult](),
 			props: map[string]interface{}{
-				"entry": prop("string", "The filename of the entry to retrieve"),
+				"entry":   prop("string", "The entry to restore"),
+				"version": prop("number", "The version number to restore; the current content is archived first"),
+			},
+			required: []string{"entry", "version"},
+		},
+		{
+			name:        "diff_entries",
+			descDefault: "Show a unified diff between two entries, entry versions, or an entry and a local file in LocalRecall collection",
+			descGeneric: "Show a unified diff between two entries (possibly in different collections), entry versions, or an entry and a local file in a LocalRecall collection",
+			handler:     DiffEntriesHandler,
+			annotations: readOnly("Diff Entries"),
+			output:      mcp.WithOutputSchema[diffResult](),
+			props: map[string]interface{}{
+				"entry":         prop("string", "The entry to diff from"),
+				"version":       prop("number", "Archived version of entry to diff from (omit for the current content)"),
+				"other_entry":   prop("string", "The entry to diff to (default: entry)"),
+				"other_version": prop("number", "Archived version of other_entry to diff to (omit for the current content)"),
+				"file_path":     prop("string", "Local file to diff to instead of another entry"),
+				"context_lines": prop("number", "Number of unchanged lines shown around each change (default: 3)"),
+				"max_tokens":    prop("number", "Approximate token budget for the diff text; longer diffs are truncated (0 or omit for the server default)"),
+				"format": map[string]interface{}{
+					"type":        "string",
+					"description": "Output format: 'markdown' renders a summary and a diff code block",
+					"enum":        []string{"json", "yaml", "markdown"},
+				},
+			},
+			crossProps: map[string]interface{}{
+				"other_collection": prop("string", "The collection of other_entry (default: collection_name)"),
 			},
 			required: []string{"entry"},
 		},
+		{
+			name:        "copy_entry",
+			descDefault: "Copy an entry in LocalRecall collection",
+			descGeneric: "Copy an entry to another name or collection in LocalRecall",
+			handler:     CopyEntryHandler,
+			confirm:     describer(confirmCopyEntry),
+			annotations: mutating("Copy Entry", true, true),
+			output:      mcp.WithOutputSchema[transferResult](),
+			props: map[string]interface{}{
+				"entry":        prop("string", "The entry to copy"),
+				"target_entry": prop("string", "Name of the copy (default: entry)"),
+				"overwrite":    prop("boolean", "Replace the target if it already exists (default: false)"),
+			},
+			crossProps: map[string]interface{}{
+				"target_collection": prop("string", "The collection to copy to (default: collection_name)"),
+			},
+			required: []string{"entry"},
+		},
+		{
+			name:        "move_entry",
+			descDefault: "Move (rename) an entry in LocalRecall collection",
+			descGeneric: "Move an entry to another name or collection in LocalRecall",
+			handler:     MoveEntryHandler,
+			confirm:     describer(confirmMoveEntry),
+			annotations: mutating("Move Entry", true, false),
+			output:      mcp.WithOutputSchema[transferResult](),
+			props: map[string]interface{}{
+				"entry":        prop("string", "The entry to move"),
+				"target_entry": prop("string", "New name of the entry (default: entry)"),
+				"overwrite":    prop("boolean", "Replace the target if it already exists (default: false)"),
+			},
+			crossProps: map[string]interface{}{
+				"target_collection": prop("string", "The collection to move to (default: collection_name)"),
+			},
+			required: []string{"entry"},
+		},
+		{
+			name:        "copy_entries",
+			descGeneric: "Copy all entries matching a glob pattern to another LocalRecall collection",
+			handler:     CopyEntriesHandler,
+			confirm:     describer(confirmCopyEntries),
+			annotations: mutating("Copy Entries", true, true),
+			output:      mcp.WithOutputSchema[bulkTransferResult](),
+			props: map[string]interface{}{
+				"pattern":           prop("string", "Glob pattern selecting the entries to copy, e.g. '*.md'"),
+				"target_collection": prop("string", "The collection to copy to"),
+				"overwrite":         prop("boolean", "Replace entries that already exist in the target (default: false, existing entries are skipped)"),
+				"dry_run":           prop("boolean", "Only report the entries that would be copied (default: false)"),
+			},
+			required:  []string{"pattern", "target_collection"},
+			crossOnly: true,
+		},
+		{
+			name:        "move_entries",
+			descGeneric: "Move all entries matching a glob pattern to another LocalRecall collection",
+			handler:     MoveEntriesHandler,
+			confirm:     describer(confirmMoveEntries),
+			annotations: mutating("Move Entries", true, false),
+			output:      mcp.WithOutputSchema[bulkTransferResult](),
+			props: map[string]interface{}{
+				"pattern":           prop("string", "Glob pattern selecting the entries to move, e.g. '*.md'"),
+				"target_collection": prop("string", "The collection to move to"),
+				"overwrite":         prop("boolean", "Replace entries that already exist in the target (default: false, existing entries are skipped)"),
+				"dry_run":           prop("boolean", "Only report the entries that would be moved (default: false)"),
+			},
+			required:  []string{"pattern", "target_collection"},
+			crossOnly: true,
+		},
 		{
 			name:        "register_source",
 			descDefault: "Register an external source for LocalRecall collection",
 			descGeneric: "Register an external source for a LocalRecall collection",
 			handler:     RegisterSourceHandler,
+			annotations: openWorld(mutating("Register Source", false, true)),
+			output:      mcp.WithOutputSchema[lrclient.SourceInfo](),
 			props: map[string]interface{}{
 				"url":             prop("string", "The URL of the external source"),
 				"update_interval": prop("number", "Update interval in seconds (0 or omit for no auto-update)"),
@@ -152,6 +516,9 @@ func (t *Toolset) GetTools(client interface{}) []toolset.ServerTool {
 			descDefault: "Remove an external source from LocalRecall collection",
 			descGeneric: "Remove an external source from a LocalRecall collection",
 			handler:     RemoveSourceHandler,
+			confirm:     describer(confirmRemoveSource),
+			annotations: mutating("Remove Source", true, true),
+			output:      mcp.WithOutputSchema[removeSourceResult](),
 			props: map[string]interface{}{
 				"url": prop("string", "The URL of the external source to remove"),
 			},
@@ -162,11 +529,16 @@ func (t *Toolset) GetTools(client interface{}) []toolset.ServerTool {
 			descDefault: "List external sources for LocalRecall collection",
 			descGeneric: "List external sources for a LocalRecall collection",
 			handler:     ListSourcesHandler,
+			annotations: readOnly("List Sources"),
+			output:      mcp.WithOutputSchema[lrclient.SourcesList](),
 		},
 	}
 
 	tools := make([]toolset.ServerTool, 0, len(collectionTools))
 	for _, def := range collectionTools {
+		if def.crossOnly && t.DefaultCollection != "" {
+			continue
+		}
 		tools = append(tools, t.buildCollectionTool(def))
 	}
 
@@ -174,7 +546,7 @@ func (t *Toolset) GetTools(client interface{}) []toolset.ServerTool {
 	if t.DefaultCollection == "" {
 		tools = append(tools,
 			toolset.ServerTool{
-				Tool: mcp.Tool{
+				Tool: withOutput(mcp.Tool{
 					Name:        "create_collection",
 					Description: "Create a new collection in LocalRecall",
 					InputSchema: mcp.ToolInputSchema{
@@ -184,11 +556,12 @@ func (t *Toolset) GetTools(client interface{}) []toolset.ServerTool {
 						},
 						Required: []string{"name"},
 					},
-				},
+					Annotations: mutating("Create Collection", false, true),
+				}, mcp.WithOutputSchema[lrclient.CollectionInfo]()),
 				Handler: CreateCollectionHandler,
 			},
 			toolset.ServerTool{
-				Tool: mcp.Tool{
+				Tool: withOutput(mcp.Tool{
 					Name:        "reset_collection",
 					Description: "Reset (clear) a collection in LocalRecall",
 					InputSchema: mcp.ToolInputSchema{
@@ -198,20 +571,80 @@ func (t *Toolset) GetTools(client interface{}) []toolset.ServerTool {
 						},
 						Required: []string{"name"},
 					},
-				},
+					Annotations: mutating("Reset Collection", true, true),
+				}, mcp.WithOutputSchema[lrclient.CollectionInfo]()),
 				Handler: ResetCollectionHandler,
+				Confirm: describer(confirmResetCollection),
 			},
 			toolset.ServerTool{
-				Tool: mcp.Tool{
+				Tool: withOutput(mcp.Tool{
 					Name:        "list_collections",
 					Description: "List all collections in LocalRecall",
 					InputSchema: mcp.ToolInputSchema{
 						Type:       "object",
 						Properties: map[string]interface{}{},
 					},
-				},
+					Annotations: readOnly("List Collections"),
+				}, mcp.WithOutputSchema[lrclient.CollectionsList]()),
 				Handler: ListCollectionsHandler,
 			},
+			toolset.ServerTool{
+				Tool: withOutput(mcp.Tool{
+					Name:        "compare_collections",
+					Description: "Compare two collections in LocalRecall: entries only in either collection, entries with differing content, and source list differences",
+					InputSchema: mcp.ToolInputSchema{
+						Type: "object",
+						Properties: map[string]interface{}{
+							"collection_a": prop("string", "The first collection, e.g. production"),
+							"collection_b": prop("string", "The second collection, e.g. staging"),
+							"format": map[string]interface{}{
+								"type":        "string",
+								"description": "Output format: 'markdown' renders a short report",
+								"enum":        []string{"json", "yaml", "markdown"},
+							},
+						},
+						Required: []string{"collection_a", "collection_b"},
+					},
+					Annotations: readOnly("Compare Collections"),
+				}, mcp.WithOutputSchema[collectionComparison]()),
+				Handler: CompareCollectionsHandler,
+			},
+			toolset.ServerTool{
+				Tool: withOutput(mcp.Tool{
+					Name:        "clone_collection",
+					Description: "Clone a LocalRecall collection: create the target and copy all entries and external sources into it",
+					InputSchema: mcp.ToolInputSchema{
+						Type: "object",
+						Properties: map[string]interface{}{
+							"source_collection": prop("string", "The collection to clone"),
+							"target_collection": prop("string", "The new collection (must not exist)"),
+							"include_sources":   prop("boolean", "Also register the source collection's external sources on the clone (default: true)"),
+							"dry_run":           prop("boolean", "Only report the entries and sources that would be copied (default: false)"),
+						},
+						Required: []string{"source_collection", "target_collection"},
+					},
+					Annotations: mutating("Clone Collection", false, false),
+				}, mcp.WithOutputSchema[CloneResult]()),
+				Handler: CloneCollectionHandler,
+			},
+			toolset.ServerTool{
+				Tool: withOutput(mcp.Tool{
+					Name:        "rename_collection",
+					Description: "Rename a LocalRecall collection: clone it to the new name, verify the copy, then reset the old collection",
+					InputSchema: mcp.ToolInputSchema{
+						Type: "object",
+						Properties: map[string]interface{}{
+							"source_collection": prop("string", "The collection to rename"),
+							"target_collection": prop("string", "The new name (must not exist)"),
+							"dry_run":           prop("boolean", "Only report the entries and sources that would be moved (default: false)"),
+						},
+						Required: []string{"source_collection", "target_collection"},
+					},
+					Annotations: mutating("Rename Collection", true, false),
+				}, mcp.WithOutputSchema[CloneResult]()),
+				Handler: RenameCollectionHandler,
+				Confirm: describer(confirmRenameCollection),
+			},
 		)
 	}
 
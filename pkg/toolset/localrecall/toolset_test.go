@@ -0,0 +1,116 @@
+package localrecall
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// toolHints are the expected annotation hints of a tool
+type toolHints struct {
+	readOnly    bool
+	destructive bool
+	idempotent  bool
+	openWorld   bool
+}
+
+var expectedToolHints = map[string]toolHints{
+	"search":                {readOnly: true, idempotent: true},
+	"ask":                   {readOnly: true, idempotent: true},
+	"add_document":          {destructive: true, idempotent: true},
+	"list_files":            {readOnly: true, idempotent: true},
+	"delete_entry":          {destructive: true, idempotent: true},
+	"get_entry_content":     {readOnly: true, idempotent: true},
+	"grep_entries":          {readOnly: true, idempotent: true},
+	"collection_stats":      {readOnly: true, idempotent: true},
+	"find_duplicates":       {destructive: true},
+	"remember":              {},
+	"recall":                {readOnly: true, idempotent: true},
+	"forget":                {destructive: true, idempotent: true},
+	"list_entry_versions":   {readOnly: true, idempotent: true},
+	"get_entry_version":     {readOnly: true, idempotent: true},
+	"restore_entry_version": {destructive: true, idempotent: true},
+	"diff_entries":          {readOnly: true, idempotent: true},
+	"copy_entry":            {destructive: true, idempotent: true},
+	"move_entry":            {destructive: true},
+	"copy_entries":          {destructive: true, idempotent: true},
+	"move_entries":          {destructive: true},
+	"register_source":       {idempotent: true, openWorld: true},
+	"remove_source":         {destructive: true, idempotent: true},
+	"list_sources":          {readOnly: true, idempotent: true},
+	"create_collection":     {idempotent: true},
+	"reset_collection":      {destructive: true, idempotent: true},
+	"list_collections":      {readOnly: true, idempotent: true},
+	"compare_collections":   {readOnly: true, idempotent: true},
+	"clone_collection":      {},
+	"rename_collection":     {destructive: true},
+}
+
+func checkHint(t *testing.T, tool, hint string, got *bool, want bool) {
+	t.Helper()
+	if got == nil {
+		t.Errorf("%s: %s is not set", tool, hint)
+		return
+	}
+	if *got != want {
+		t.Errorf("%s: expected %s %v, got %v", tool, hint, want, *got)
+	}
+}
+
+func checkToolAnnotations(t *testing.T, tool mcp.Tool) {
+	t.Helper()
+	want, ok := expectedToolHints[tool.Name]
+	if !ok {
+		t.Errorf("%s: no expected annotations; add the tool to expectedToolHints", tool.Name)
+		return
+	}
+
+	a := tool.Annotations
+	if a.Title == "" {
+		t.Errorf("%s: title is not set", tool.Name)
+	}
+	checkHint(t, tool.Name, "readOnlyHint", a.ReadOnlyHint, want.readOnly)
+	checkHint(t, tool.Name, "destructiveHint", a.DestructiveHint, want.destructive)
+	checkHint(t, tool.Name, "idempotentHint", a.IdempotentHint, want.idempotent)
+	checkHint(t, tool.Name, "openWorldHint", a.OpenWorldHint, want.openWorld)
+}
+
+func TestGetTools_Annotations(t *testing.T) {
+	ts := &Toolset{}
+	tools := ts.GetTools(nil)
+	if len(tools) != len(expectedToolHints) {
+		t.Errorf("Expected %d tools, got %d", len(expectedToolHints), len(tools))
+	}
+	for _, tool := range tools {
+		checkToolAnnotations(t, tool.Tool)
+	}
+}
+
+func TestGetTools_AnnotationsWithDefaultCollection(t *testing.T) {
+	ts := &Toolset{DefaultCollection: "docs"}
+	for _, tool := range ts.GetTools(nil) {
+		checkToolAnnotations(t, tool.Tool)
+	}
+}
+
+func TestGetTools_ReadOnlyToolsAreNotDestructive(t *testing.T) {
+	ts := &Toolset{}
+	for _, tool := range ts.GetTools(nil) {
+		a := tool.Tool.Annotations
+		if a.ReadOnlyHint != nil && *a.ReadOnlyHint && a.DestructiveHint != nil && *a.DestructiveHint {
+			t.Errorf("%s: read-only tool is marked destructive", tool.Tool.Name)
+		}
+	}
+}
+
+func TestGetTools_Titles(t *testing.T) {
+	ts := &Toolset{}
+	titles := make(map[string]string)
+	for _, tool := range ts.GetTools(nil) {
+		title := tool.Tool.Annotations.Title
+		if other, ok := titles[title]; ok {
+			t.Errorf("%s and %s share the title %q", other, tool.Tool.Name, title)
+		}
+		titles[title] = tool.Tool.Name
+	}
+}
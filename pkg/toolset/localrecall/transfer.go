@@ -0,0 +1,301 @@
+package localrecall
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/core/logging"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
+)
+
+// Transfer operations
+const (
+	opCopy = "copy"
+	opMove = "move"
+)
+
+// transferResult describes a single copied or moved entry
+type transferResult struct {
+	Operation       string `json:"operation" yaml:"operation"`
+	From            string `json:"from" yaml:"from"`
+	To              string `json:"to" yaml:"to"`
+	Bytes           int    `json:"bytes" yaml:"bytes"`
+	Overwritten     bool   `json:"overwritten,omitempty" yaml:"overwritten,omitempty"`
+	ArchivedVersion int    `json:"archived_version,omitempty" yaml:"archived_version,omitempty"`
+	Verified        bool   `json:"verified" yaml:"verified"`
+	SourceDeleted   bool   `json:"source_deleted,omitempty" yaml:"source_deleted,omitempty"`
+}
+
+// bulkTransferResult is the response of copy_entries and move_entries
+type bulkTransferResult struct {
+	Operation        string           `json:"operation" yaml:"operation"`
+	SourceCollection string           `json:"source_collection" yaml:"source_collection"`
+	TargetCollection string           `json:"target_collection" yaml:"target_collection"`
+	Pattern          string           `json:"pattern" yaml:"pattern"`
+	DryRun           bool             `json:"dry_run" yaml:"dry_run"`
+	Matched          []string         `json:"matched,omitempty" yaml:"matched,omitempty"`
+	Transferred      []transferResult `json:"transferred" yaml:"transferred"`
+	Skipped          []entryError     `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+	Errors           []entryError     `json:"errors,omitempty" yaml:"errors,omitempty"`
+	Count            int              `json:"count" yaml:"count"`
+}
+
+// transferRequest describes a copy or move of one entry
+type transferRequest struct {
+	operation        string
+	sourceCollection string
+	sourceEntry      string
+	targetCollection string
+	targetEntry      string
+	overwrite        bool
+}
+
+// errTargetExists is returned when the target entry exists and overwrite is not set
+type errTargetExists struct {
+	target string
+}
+
+func (e *errTargetExists) Error() string {
+	return fmt.Sprintf("%s already exists (set overwrite to replace it)", e.target)
+}
+
+// normalizeContent collapses whitespace so re-chunked content compares equal
+func normalizeContent(content string) string {
+	return strings.Join(strings.Fields(content), " ")
+}
+
+// replaceEntry stores content under an entry. An existing entry is archived
+// (when versioning is enabled) and removed first so it is replaced rather
+// than re-uploaded over. It returns the archived version number, if any.
+func replaceEntry(ctx context.Context, client *toolset.LocalRecallClient, collection, entry, content string, exists bool, reason string) (int, error) {
+	archived := 0
+	if exists {
+		var err error
+		archived, err = archiveCurrent(ctx, client, collection, entry, reason)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := client.Client.DeleteEntry(ctx, collection, entry); err != nil {
+			return 0, fmt.Errorf("could not remove current content of %s: %w", entry, err)
+		}
+		client.InvalidateEntry(collection, entry)
+	}
+
+	if _, err := client.Client.AddDocument(ctx, collection, entry, []byte(content)); err != nil {
+		if archived > 0 {
+			return archived, fmt.Errorf("failed to store %s after removing it; its previous content is saved as version %d: %w", entry, archived, err)
+		}
+		return archived, fmt.Errorf("failed to store %s: %w", entry, err)
+	}
+	client.InvalidateEntry(collection, entry)
+	return archived, nil
+}
+
+// transferEntry copies an entry and, for moves, deletes the source once the
+// copy has been read back and verified
+func transferEntry(ctx context.Context, client *toolset.LocalRecallClient, req transferRequest, targetExists bool) (transferResult, error) {
+	result := transferResult{
+		Operation: req.operation,
+		From:      req.sourceCollection + "/" + req.sourceEntry,
+		To:        req.targetCollection + "/" + req.targetEntry,
+	}
+	if targetExists && !req.overwrite {
+		return result, &errTargetExists{target: result.To}
+	}
+
+	source, err := client.Client.GetEntryContent(ctx, req.sourceCollection, req.sourceEntry)
+	if err != nil {
+		return result, fmt.Errorf("failed to read %s: %w", result.From, err)
+	}
+	result.Bytes = len(source.Content)
+
+	result.ArchivedVersion, err = replaceEntry(ctx, client, req.targetCollection, req.targetEntry, source.Content, targetExists, archiveReasonUpdate)
+	if err != nil {
+		return result, err
+	}
+	result.Overwritten = targetExists
+	// A schedule left by the overwritten entry must not delete the copy
+	forgetExpiry(client, req.targetCollection, req.targetEntry)
+
+	// Verify the copy before touching the source
+	copied, err := client.Client.GetEntryContent(ctx, req.targetCollection, req.targetEntry)
+	if err != nil {
+		return result, fmt.Errorf("failed to verify %s: %w", result.To, err)
+	}
+	if normalizeContent(copied.Content) != normalizeContent(source.Content) {
+		return result, fmt.Errorf("verification failed: %s does not match %s", result.To, result.From)
+	}
+	result.Verified = true
+
+	if req.operation != opMove {
+		return result, nil
+	}
+
+	if _, err := client.Client.DeleteEntry(ctx, req.sourceCollection, req.sourceEntry); err != nil {
+		return result, fmt.Errorf("copied to %s but failed to delete %s: %w", result.To, result.From, err)
+	}
+	client.InvalidateEntry(req.sourceCollection, req.sourceEntry)
+	result.SourceDeleted = true
+
+	// The expiry schedule follows the entry
+	if record, ok := client.Expiry.Get(req.sourceCollection, req.sourceEntry); ok {
+		if err := client.Expiry.Set(req.targetCollection, req.targetEntry, record.ExpiresAt); err != nil {
+			logging.Warn("Failed to move expiry of %s to %s: %v", result.From, result.To, err)
+		}
+		forgetExpiry(client, req.sourceCollection, req.sourceEntry)
+	}
+	return result, nil
+}
+
+// CopyEntryHandler handles copy_entry requests
+func CopyEntryHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
+	return transferEntryHandler(ctx, clientInterface, params, opCopy)
+}
+
+// MoveEntryHandler handles move_entry requests
+func MoveEntryHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
+	return transferEntryHandler(ctx, clientInterface, params, opMove)
+}
+
+// transferEntryHandler copies or moves a single entry
+func transferEntryHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}, operation string) (string, error) {
+	client, err := getClient(clientInterface)
+	if err != nil {
+		return "", err
+	}
+
+	collectionName := handler.GetStringParam(params, "collection_name", "")
+
+	entry, err := handler.RequireStringParam(params, "entry")
+	if err != nil {
+		return "", err
+	}
+
+	req := transferRequest{
+		operation:        operation,
+		sourceCollection: collectionName,
+		sourceEntry:      entry,
+		targetCollection: handler.GetStringParam(params, "target_collection", collectionName),
+		targetEntry:      handler.GetStringParam(params, "target_entry", entry),
+		overwrite:        handler.GetBoolParam(params, "overwrite", false),
+	}
+	format := handler.GetStringParam(params, "format", "json")
+
+	if req.targetCollection == req.sourceCollection && req.targetEntry == req.sourceEntry {
+		return "", fmt.Errorf("source and target are the same entry")
+	}
+
+	files, err := client.Client.ListFiles(ctx, req.targetCollection)
+	if err != nil {
+		return "", fmt.Errorf("list files of %s failed: %w", req.targetCollection, err)
+	}
+
+	result, err := transferEntry(ctx, client, req, slices.Contains(files.Entries, req.targetEntry))
+	if err != nil {
+		return "", fmt.Errorf("%s entry failed: %w", operation, err)
+	}
+
+	return handler.FormatResult(ctx, result, format)
+}
+
+// CopyEntriesHandler handles copy_entries requests
+func CopyEntriesHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
+	return transferEntriesHandler(ctx, clientInterface, params, opCopy)
+}
+
+// MoveEntriesHandler handles move_entries requests
+func MoveEntriesHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
+	return transferEntriesHandler(ctx, clientInterface, params, opMove)
+}
+
+// transferEntriesHandler copies or moves all entries matching a glob pattern
+func transferEntriesHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}, operation string) (string, error) {
+	client, err := getClient(clientInterface)
+	if err != nil {
+		return "", err
+	}
+
+	collectionName := handler.GetStringParam(params, "collection_name", "")
+
+	pattern, err := handler.RequireStringParam(params, "pattern")
+	if err != nil {
+		return "", err
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return "", fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	targetCollection, err := handler.RequireStringParam(params, "target_collection")
+	if err != nil {
+		return "", err
+	}
+	if targetCollection == collectionName {
+		return "", fmt.Errorf("target_collection must differ from the source collection")
+	}
+
+	overwrite := handler.GetBoolParam(params, "overwrite", false)
+	dryRun := handler.GetBoolParam(params, "dry_run", false)
+	format := handler.GetStringParam(params, "format", "json")
+
+	sourceFiles, err := client.Client.ListFiles(ctx, collectionName)
+	if err != nil {
+		return "", fmt.Errorf("list files of %s failed: %w", collectionName, err)
+	}
+	targetFiles, err := client.Client.ListFiles(ctx, targetCollection)
+	if err != nil {
+		return "", fmt.Errorf("list files of %s failed: %w", targetCollection, err)
+	}
+
+	result := &bulkTransferResult{
+		Operation:        operation,
+		SourceCollection: collectionName,
+		TargetCollection: targetCollection,
+		Pattern:          pattern,
+		DryRun:           dryRun,
+		Transferred:      []transferResult{},
+	}
+
+	for _, entry := range sourceFiles.Entries {
+		if ok, _ := path.Match(pattern, entry); !ok {
+			continue
+		}
+		exists := slices.Contains(targetFiles.Entries, entry)
+		if dryRun {
+			if exists && !overwrite {
+				result.Skipped = append(result.Skipped, entryError{Entry: entry, Error: "target already exists"})
+				continue
+			}
+			result.Matched = append(result.Matched, entry)
+			continue
+		}
+
+		transferred, err := transferEntry(ctx, client, transferRequest{
+			operation:        operation,
+			sourceCollection: collectionName,
+			sourceEntry:      entry,
+			targetCollection: targetCollection,
+			targetEntry:      entry,
+			overwrite:        overwrite,
+		}, exists)
+		if _, skipped := err.(*errTargetExists); skipped {
+			result.Skipped = append(result.Skipped, entryError{Entry: entry, Error: "target already exists"})
+			continue
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, entryError{Entry: entry, Error: err.Error()})
+			continue
+		}
+		result.Transferred = append(result.Transferred, transferred)
+	}
+	if dryRun {
+		result.Count = len(result.Matched)
+	} else {
+		result.Count = len(result.Transferred)
+	}
+
+	return handler.FormatResult(ctx, result, format)
+}
@@ -0,0 +1,189 @@
+package localrecall
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/futuretea/localrecall-mcp-server/internal/lrtest"
+	"github.com/futuretea/localrecall-mcp-server/pkg/expiry"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+)
+
+func TestCopyEntryHandler(t *testing.T) {
+	server, client := newTestClient(t)
+	server.AddEntry("docs", "a.md", "alpha")
+	server.AddEntry("docs", "b.md", "beta")
+	server.AddCollection("kb")
+
+	result := callToolJSON[transferResult](t, client, CopyEntryHandler, map[string]interface{}{
+		"collection_name": "docs", "entry": "a.md", "target_collection": "kb",
+	})
+	if result.To != "kb/a.md" || !result.Verified || result.Overwritten || result.SourceDeleted || result.Bytes != 5 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if content, _ := server.Entry("kb", "a.md"); content != "alpha" {
+		t.Errorf("Expected the copy in kb, got %q", content)
+	}
+	if _, ok := server.Entry("docs", "a.md"); !ok {
+		t.Error("Expected a copy to keep its source")
+	}
+
+	conflict := map[string]interface{}{"collection_name": "docs", "entry": "a.md", "target_entry": "b.md"}
+	if _, err := callTool(client, CopyEntryHandler, conflict); err == nil || !strings.Contains(err.Error(), "docs/b.md already exists") {
+		t.Errorf("Expected an existing target to be refused, got %v", err)
+	}
+	if content, _ := server.Entry("docs", "b.md"); content != "beta" {
+		t.Errorf("Expected the target to be unchanged, got %q", content)
+	}
+
+	conflict["overwrite"] = true
+	result = callToolJSON[transferResult](t, client, CopyEntryHandler, conflict)
+	if !result.Overwritten {
+		t.Errorf("Expected the target to be overwritten, got %+v", result)
+	}
+	if content, _ := server.Entry("docs", "b.md"); content != "alpha" {
+		t.Errorf("Expected the target to be replaced, got %q", content)
+	}
+
+	if _, err := callTool(client, CopyEntryHandler, map[string]interface{}{"collection_name": "docs", "entry": "a.md"}); err == nil {
+		t.Error("Expected copying an entry onto itself to be refused")
+	}
+}
+
+func TestMoveEntryHandler(t *testing.T) {
+	server, client := newTestClient(t)
+	var err error
+	if client.Expiry, err = expiry.Load(filepath.Join(t.TempDir(), "expiry.json")); err != nil {
+		t.Fatal(err)
+	}
+	server.AddEntry("docs", "a.md", "alpha")
+	server.AddCollection("kb")
+	expiresAt := time.Now().Add(time.Hour)
+	if err := client.Expiry.Set("docs", "a.md", expiresAt); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callToolJSON[transferResult](t, client, MoveEntryHandler, map[string]interface{}{
+		"collection_name": "docs", "entry": "a.md", "target_collection": "kb", "target_entry": "moved.md",
+	})
+	if !result.Verified || !result.SourceDeleted {
+		t.Errorf("Expected a verified move, got %+v", result)
+	}
+	if _, ok := server.Entry("docs", "a.md"); ok {
+		t.Error("Expected the source to be deleted")
+	}
+	if _, ok := client.Expiry.Get("docs", "a.md"); ok {
+		t.Error("Expected the source's expiry to be cleared")
+	}
+	if record, ok := client.Expiry.Get("kb", "moved.md"); !ok || !record.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("Expected the expiry to follow the entry, got %+v (%v)", record, ok)
+	}
+}
+
+func TestMoveEntryHandler_KeepsSourceOnFailure(t *testing.T) {
+	tests := []struct {
+		name                 string
+		failUpload, failRead bool
+		want                 string
+	}{
+		{name: "upload fails", failUpload: true, want: "failed to store a.md"},
+		{name: "verification read fails", failRead: true, want: "failed to verify kb/a.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := newTestClient(t)
+			server.AddEntry("docs", "a.md", "alpha")
+			server.AddCollection("kb")
+			server.FailUpload = func(collection, _ string) bool { return tt.failUpload && collection == "kb" }
+			server.FailRead = func(collection, _ string) bool { return tt.failRead && collection == "kb" }
+
+			_, err := callTool(client, MoveEntryHandler, map[string]interface{}{"collection_name": "docs", "entry": "a.md", "target_collection": "kb"})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+			if content, ok := server.Entry("docs", "a.md"); !ok || content != "alpha" {
+				t.Error("Expected the source to be kept when the copy is not verified")
+			}
+		})
+	}
+}
+
+func TestTransferEntriesHandler(t *testing.T) {
+	setup := func(t *testing.T) (*lrtest.Server, *toolset.LocalRecallClient, map[string]interface{}) {
+		server, client := newTestClient(t)
+		server.AddEntry("docs", "a.md", "alpha")
+		server.AddEntry("docs", "b.md", "beta")
+		server.AddEntry("docs", "notes.txt", "notes")
+		server.AddEntry("docs", "sub-c.md", "gamma")
+		server.AddEntry("kb", "b.md", "old beta")
+		return server, client, map[string]interface{}{
+			"collection_name": "docs", "pattern": "*.md", "target_collection": "kb",
+		}
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		server, client, params := setup(t)
+		params["dry_run"] = true
+		result := callToolJSON[bulkTransferResult](t, client, MoveEntriesHandler, params)
+		if !slices.Equal(result.Matched, []string{"a.md", "sub-c.md"}) || len(result.Skipped) != 1 || result.Skipped[0].Entry != "b.md" || result.Count != 2 {
+			t.Errorf("Unexpected dry run %+v", result)
+		}
+		if entries := server.Entries("docs"); len(entries) != 4 {
+			t.Errorf("Expected a dry run to change nothing, got %v", entries)
+		}
+	})
+
+	t.Run("copy skips existing targets", func(t *testing.T) {
+		server, client, params := setup(t)
+		result := callToolJSON[bulkTransferResult](t, client, CopyEntriesHandler, params)
+		if result.Count != 2 || len(result.Skipped) != 1 || result.Skipped[0].Entry != "b.md" {
+			t.Errorf("Unexpected result %+v", result)
+		}
+		if content, _ := server.Entry("kb", "b.md"); content != "old beta" {
+			t.Errorf("Expected the existing target to be kept, got %q", content)
+		}
+		if _, ok := server.Entry("kb", "notes.txt"); ok {
+			t.Error("Expected entries not matching the pattern to be left out")
+		}
+	})
+
+	t.Run("copy overwrites", func(t *testing.T) {
+		server, client, params := setup(t)
+		params["overwrite"] = true
+		result := callToolJSON[bulkTransferResult](t, client, CopyEntriesHandler, params)
+		if result.Count != 3 || len(result.Skipped) != 0 {
+			t.Errorf("Unexpected result %+v", result)
+		}
+		if content, _ := server.Entry("kb", "b.md"); content != "beta" {
+			t.Errorf("Expected the existing target to be replaced, got %q", content)
+		}
+	})
+
+	t.Run("move deletes only verified copies", func(t *testing.T) {
+		server, client, params := setup(t)
+		server.FailUpload = func(collection, entry string) bool { return collection == "kb" && entry == "sub-c.md" }
+		result := callToolJSON[bulkTransferResult](t, client, MoveEntriesHandler, params)
+		if result.Count != 1 || result.Transferred[0].From != "docs/a.md" || len(result.Errors) != 1 || result.Errors[0].Entry != "sub-c.md" {
+			t.Errorf("Unexpected result %+v", result)
+		}
+		if entries := server.Entries("docs"); !slices.Equal(entries, []string{"b.md", "notes.txt", "sub-c.md"}) {
+			t.Errorf("Expected only the moved entry to be deleted, got %v", entries)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, client, _ := setup(t)
+		for _, params := range []map[string]interface{}{
+			{"collection_name": "docs", "pattern": "[", "target_collection": "kb"},
+			{"collection_name": "docs", "pattern": "*.md", "target_collection": "docs"},
+			{"collection_name": "docs", "pattern": "*.md"},
+		} {
+			if _, err := callTool(client, CopyEntriesHandler, params); err == nil {
+				t.Errorf("Expected %v to be rejected", params)
+			}
+		}
+	})
+}
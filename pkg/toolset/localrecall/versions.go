@@ -0,0 +1,226 @@
+package localrecall
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset"
+	"github.com/futuretea/localrecall-mcp-server/pkg/toolset/handler"
+	"github.com/futuretea/localrecall-mcp-server/pkg/versions"
+)
+
+// Reasons recorded with archived versions
+const (
+	archiveReasonUpdate  = "update"
+	archiveReasonRestore = "restore"
+)
+
+// entryVersionsResult is the response of list_entry_versions
+type entryVersionsResult struct {
+	Collection string             `json:"collection" yaml:"collection"`
+	Entry      string             `json:"entry" yaml:"entry"`
+	Exists     bool               `json:"exists" yaml:"exists"`
+	Versions   []versions.Version `json:"versions" yaml:"versions"`
+	Count      int                `json:"count" yaml:"count"`
+}
+
+// entryVersion is the response of get_entry_version
+type entryVersion struct {
+	versions.Version `yaml:",inline"`
+	Content          string `json:"content" yaml:"content"`
+}
+
+// restoreResult is the response of restore_entry_version
+type restoreResult struct {
+	Collection      string `json:"collection" yaml:"collection"`
+	Entry           string `json:"entry" yaml:"entry"`
+	RestoredVersion int    `json:"restored_version" yaml:"restored_version"`
+	ArchivedVersion int    `json:"archived_version,omitempty" yaml:"archived_version,omitempty"`
+	Unchanged       bool   `json:"unchanged" yaml:"unchanged"`
+}
+
+// checkVersioningEnabled returns an error if the server has no version store configured
+func checkVersioningEnabled(client *toolset.LocalRecallClient) error {
+	if client.Versions == nil {
+		return fmt.Errorf("entry versioning is not enabled on this server (set version_store_path)")
+	}
+	return nil
+}
+
+// requireVersionParam reads the required version number parameter
+func requireVersionParam(params map[string]interface{}) (int, error) {
+	if _, ok := params["version"]; !ok {
+		return 0, fmt.Errorf("version parameter is required")
+	}
+	version := handler.GetIntParam(params, "version", 0)
+	if version < 1 {
+		return 0, fmt.Errorf("version must be a positive integer")
+	}
+	return version, nil
+}
+
+// currentContent fetches the live content of an entry, bypassing the cache.
+// The second return value is false if the entry does not exist. Other read
+// failures are returned, so that callers do not mistake an unreachable entry
+// for a missing one and overwrite it without archiving.
+func currentContent(ctx context.Context, client *toolset.LocalRecallClient, collection, entry string) (string, bool, error) {
+	content, err := client.Client.GetEntryContent(ctx, collection, entry)
+	if err == nil {
+		return content.Content, true, nil
+	}
+
+	// The API does not tell a missing entry apart from other failures
+	files, listErr := client.Client.ListFiles(ctx, collection)
+	if listErr != nil {
+		return "", false, fmt.Errorf("failed to read %s: %w", entry, err)
+	}
+	if !slices.Contains(files.Entries, entry) {
+		return "", false, nil
+	}
+	return "", false, fmt.Errorf("failed to read %s: %w", entry, err)
+}
+
+// archiveCurrent archives the live content of an entry before it is
+// overwritten. It returns the archived version number, or 0 if versioning is
+// disabled or the entry does not exist yet.
+func archiveCurrent(ctx context.Context, client *toolset.LocalRecallClient, collection, entry, reason string) (int, error) {
+	if client.Versions == nil {
+		return 0, nil
+	}
+	content, ok, err := currentContent(ctx, client, collection, entry)
+	if err != nil {
+		return 0, fmt.Errorf("could not archive previous version: %w", err)
+	}
+	if !ok {
+		return 0, nil
+	}
+	v, err := client.Versions.Archive(collection, entry, content, reason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive previous version of %s: %w", entry, err)
+	}
+	return v.Number, nil
+}
+
+// ListEntryVersionsHandler lists the archived versions of an entry
+func ListEntryVersionsHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
+	client, err := getClient(clientInterface)
+	if err != nil {
+		return "", err
+	}
+	if err := checkVersioningEnabled(client); err != nil {
+		return "", err
+	}
+
+	collectionName := handler.GetStringParam(params, "collection_name", "")
+
+	entry, err := handler.RequireStringParam(params, "entry")
+	if err != nil {
+		return "", err
+	}
+
+	format := handler.GetStringParam(params, "format", "json")
+
+	list, err := client.Versions.List(collectionName, entry)
+	if err != nil {
+		return "", err
+	}
+	_, exists, err := currentContent(ctx, client, collectionName, entry)
+	if err != nil {
+		return "", err
+	}
+
+	result := &entryVersionsResult{
+		Collection: collectionName,
+		Entry:      entry,
+		Exists:     exists,
+		Versions:   list,
+		Count:      len(list),
+	}
+	return handler.FormatResult(ctx, result, format)
+}
+
+// GetEntryVersionHandler returns the content of an archived version
+func GetEntryVersionHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
+	client, err := getClient(clientInterface)
+	if err != nil {
+		return "", err
+	}
+	if err := checkVersioningEnabled(client); err != nil {
+		return "", err
+	}
+
+	collectionName := handler.GetStringParam(params, "collection_name", "")
+
+	entry, err := handler.RequireStringParam(params, "entry")
+	if err != nil {
+		return "", err
+	}
+
+	version, err := requireVersionParam(params)
+	if err != nil {
+		return "", err
+	}
+
+	format := handler.GetStringParam(params, "format", "json")
+
+	v, content, err := client.Versions.Get(collectionName, entry, version)
+	if err != nil {
+		return "", err
+	}
+
+	return handler.FormatResult(ctx, &entryVersion{Version: v, Content: content}, format)
+}
+
+// RestoreEntryVersionHandler replaces an entry with an archived version.
+// The current content is archived first so the restore can be undone.
+func RestoreEntryVersionHandler(ctx context.Context, clientInterface interface{}, params map[string]interface{}) (string, error) {
+	client, err := getClient(clientInterface)
+	if err != nil {
+		return "", err
+	}
+	if err := checkVersioningEnabled(client); err != nil {
+		return "", err
+	}
+
+	collectionName := handler.GetStringParam(params, "collection_name", "")
+
+	entry, err := handler.RequireStringParam(params, "entry")
+	if err != nil {
+		return "", err
+	}
+
+	version, err := requireVersionParam(params)
+	if err != nil {
+		return "", err
+	}
+
+	format := handler.GetStringParam(params, "format", "json")
+
+	v, content, err := client.Versions.Get(collectionName, entry, version)
+	if err != nil {
+		return "", err
+	}
+
+	result := &restoreResult{
+		Collection:      collectionName,
+		Entry:           entry,
+		RestoredVersion: v.Number,
+	}
+
+	current, exists, err := currentContent(ctx, client, collectionName, entry)
+	if err != nil {
+		return "", fmt.Errorf("restore failed: %w", err)
+	}
+	if exists && versions.Hash(current) == v.SHA256 {
+		result.Unchanged = true
+		return handler.FormatResult(ctx, result, format)
+	}
+
+	result.ArchivedVersion, err = replaceEntry(ctx, client, collectionName, entry, content, exists, archiveReasonRestore)
+	if err != nil {
+		return "", fmt.Errorf("restore failed: %w", err)
+	}
+
+	return handler.FormatResult(ctx, result, format)
+}
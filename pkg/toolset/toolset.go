@@ -1,6 +1,8 @@
 package toolset
 
 import (
+	"context"
+
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -23,7 +25,17 @@ type ServerTool struct {
 
 	// Handler is the function that handles tool calls
 	Handler ToolHandler
+
+	// Confirm describes a call of a destructive tool for the user to confirm.
+	// Destructive tools without one get a generic message.
+	Confirm ConfirmFunc
 }
 
-// ToolHandler is the function signature for handling tool calls
-type ToolHandler func(client interface{}, params map[string]interface{}) (string, error)
+// ToolHandler is the function signature for handling tool calls. The context
+// is cancelled when the MCP request is cancelled or the tool times out.
+type ToolHandler func(ctx context.Context, client interface{}, params map[string]interface{}) (string, error)
+
+// ConfirmFunc describes the effect of a tool call for the user to confirm,
+// showing what would be deleted or overwritten, or returns "" when the call
+// has nothing to confirm, such as an add_document call creating a new entry
+type ConfirmFunc func(ctx context.Context, client interface{}, params map[string]interface{}) string
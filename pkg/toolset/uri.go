@@ -0,0 +1,84 @@
+package toolset
+
+import (
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ResourceScheme is the URI scheme of the MCP resources exposed by the server
+const ResourceScheme = "localrecall://"
+
+// CollectionsURI is the resource URI listing all collections. Its empty
+// collection segment keeps it apart from the URI of any collection, including
+// one named "collections".
+const CollectionsURI = ResourceScheme + "/collections"
+
+// CollectionURI returns the resource URI describing a collection
+func CollectionURI(collection string) string {
+	return ResourceScheme + url.PathEscape(collection)
+}
+
+// EntriesURI returns the resource URI listing the entries of a collection
+func EntriesURI(collection string) string {
+	return CollectionURI(collection) + "/entries"
+}
+
+// EntryURI returns the resource URI of an entry's content
+func EntryURI(collection, entry string) string {
+	return EntriesURI(collection) + "/" + url.PathEscape(entry)
+}
+
+// ChunkURI returns a URI identifying one chunk of an entry's content: the
+// entry URI with the chunk ID as fragment. It only labels partial text; the
+// entry itself is read through EntryURI.
+func ChunkURI(collection, entry, chunk string) string {
+	return EntryURI(collection, entry) + "#chunk=" + url.PathEscape(chunk)
+}
+
+// ParseResourceURI returns the collection and entry named by a collection,
+// entry list or entry URI. The entry is empty unless the URI names an entry.
+// ok is false for CollectionsURI and URIs not produced by this package.
+// Fragments, such as those of chunk URIs, are ignored.
+func ParseResourceURI(uri string) (collection, entry string, ok bool) {
+	uri, _, _ = strings.Cut(uri, "#")
+	rest, found := strings.CutPrefix(uri, ResourceScheme)
+	if !found || uri == CollectionsURI {
+		return "", "", false
+	}
+
+	parts := strings.Split(rest, "/")
+	if len(parts) > 3 || (len(parts) > 1 && parts[1] != "entries") {
+		return "", "", false
+	}
+	collection, err := url.PathUnescape(parts[0])
+	if err != nil || collection == "" {
+		return "", "", false
+	}
+	if len(parts) == 3 {
+		entry, err = url.PathUnescape(parts[2])
+		if err != nil || entry == "" {
+			return "", "", false
+		}
+	}
+	return collection, entry, true
+}
+
+// EntryMIMEType returns the MIME type of the content LocalRecall returns for
+// an entry. Text files keep their type; the text extracted from other files,
+// such as PDF or Word documents, is plain text.
+func EntryMIMEType(entry string) string {
+	switch ext := path.Ext(entry); ext {
+	case ".md", ".markdown":
+		return "text/markdown"
+	case "":
+		return "text/plain"
+	default:
+		t, _, _ := strings.Cut(mime.TypeByExtension(ext), ";")
+		if strings.HasPrefix(t, "text/") || t == "application/json" || t == "application/xml" {
+			return t
+		}
+		return "text/plain"
+	}
+}
@@ -0,0 +1,234 @@
+package versions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Version describes an archived revision of an entry
+type Version struct {
+	Collection string    `json:"collection" yaml:"collection"`
+	Entry      string    `json:"entry" yaml:"entry"`
+	Number     int       `json:"version" yaml:"version"`
+	ArchivedAt time.Time `json:"archived_at" yaml:"archived_at"`
+	Reason     string    `json:"reason" yaml:"reason"`
+	Bytes      int       `json:"bytes" yaml:"bytes"`
+	SHA256     string    `json:"sha256" yaml:"sha256"`
+}
+
+// archivedVersion is the on-disk form of a version
+type archivedVersion struct {
+	Version
+	Content string `json:"content"`
+}
+
+// Store keeps prior revisions of entries on the local filesystem, one
+// directory per entry and one JSON file per version.
+type Store struct {
+	mu          sync.Mutex
+	root        string
+	maxVersions int
+}
+
+// Open opens the version store rooted at root, creating it if needed.
+// maxVersions limits the versions kept per entry (0 = unlimited).
+func Open(root string, maxVersions int) (*Store, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create version store: %w", err)
+	}
+	return &Store{root: root, maxVersions: maxVersions}, nil
+}
+
+// Hash returns the content hash recorded for a version
+func Hash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// escapeName turns a collection or entry name into a single path element.
+// Dot segments are escaped too, so that names like ".." stay inside the store.
+func escapeName(name string) string {
+	escaped := url.PathEscape(name)
+	if strings.Trim(escaped, ".") == "" {
+		escaped = strings.ReplaceAll(escaped, ".", "%2E")
+	}
+	return escaped
+}
+
+// entryDir returns the directory holding the versions of an entry
+func (s *Store) entryDir(collection, entry string) (string, error) {
+	if collection == "" || entry == "" {
+		return "", fmt.Errorf("collection and entry names must not be empty")
+	}
+	dir := filepath.Join(s.root, escapeName(collection), escapeName(entry))
+	if rel, err := filepath.Rel(s.root, dir); err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("invalid version path for %s/%s", collection, entry)
+	}
+	return dir, nil
+}
+
+// Archive records content as the newest version of an entry. Content
+// identical to the newest version is not archived again.
+func (s *Store) Archive(collection, entry, content, reason string) (Version, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir, err := s.entryDir(collection, entry)
+	if err != nil {
+		return Version{}, err
+	}
+	numbers, err := s.numbers(dir, entry)
+	if err != nil {
+		return Version{}, err
+	}
+
+	hash := Hash(content)
+	next := 1
+	if len(numbers) > 0 {
+		latest, _, err := s.read(dir, entry, numbers[len(numbers)-1])
+		if err != nil {
+			return Version{}, err
+		}
+		if latest.SHA256 == hash {
+			return latest, nil
+		}
+		next = latest.Number + 1
+	}
+
+	v := archivedVersion{
+		Version: Version{
+			Collection: collection,
+			Entry:      entry,
+			Number:     next,
+			ArchivedAt: time.Now().UTC(),
+			Reason:     reason,
+			Bytes:      len(content),
+			SHA256:     hash,
+		},
+		Content: content,
+	}
+	if err := s.write(dir, v); err != nil {
+		return Version{}, err
+	}
+
+	numbers = append(numbers, next)
+	if s.maxVersions > 0 && len(numbers) > s.maxVersions {
+		for _, n := range numbers[:len(numbers)-s.maxVersions] {
+			if err := os.Remove(versionPath(dir, n)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return v.Version, fmt.Errorf("failed to prune version %d of %s: %w", n, entry, err)
+			}
+		}
+	}
+	return v.Version, nil
+}
+
+// List returns the archived versions of an entry, newest first
+func (s *Store) List(collection, entry string) ([]Version, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir, err := s.entryDir(collection, entry)
+	if err != nil {
+		return nil, err
+	}
+	numbers, err := s.numbers(dir, entry)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]Version, 0, len(numbers))
+	for i := len(numbers) - 1; i >= 0; i-- {
+		v, _, err := s.read(dir, entry, numbers[i])
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+// Get returns an archived version of an entry and its content
+func (s *Store) Get(collection, entry string, number int) (Version, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dir, err := s.entryDir(collection, entry)
+	if err != nil {
+		return Version{}, "", err
+	}
+	return s.read(dir, entry, number)
+}
+
+// versionPath returns the file holding a version in an entry directory
+func versionPath(dir string, number int) string {
+	return filepath.Join(dir, strconv.Itoa(number)+".json")
+}
+
+// numbers returns the version numbers of the entry stored in dir in
+// ascending order. Callers must hold s.mu.
+func (s *Store) numbers(dir, entry string) ([]int, error) {
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read versions of %s: %w", entry, err)
+	}
+	var numbers []int
+	for _, f := range files {
+		name, ok := strings.CutSuffix(f.Name(), ".json")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(name); err == nil {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+	return numbers, nil
+}
+
+// read loads a version of the entry stored in dir. Callers must hold s.mu.
+func (s *Store) read(dir, entry string, number int) (Version, string, error) {
+	data, err := os.ReadFile(versionPath(dir, number))
+	if errors.Is(err, os.ErrNotExist) {
+		return Version{}, "", fmt.Errorf("version %d of %s not found", number, entry)
+	}
+	if err != nil {
+		return Version{}, "", fmt.Errorf("failed to read version %d of %s: %w", number, entry, err)
+	}
+	var v archivedVersion
+	if err := json.Unmarshal(data, &v); err != nil {
+		return Version{}, "", fmt.Errorf("failed to parse version %d of %s: %w", number, entry, err)
+	}
+	return v.Version, v.Content, nil
+}
+
+// write stores a version atomically in dir. Callers must hold s.mu.
+func (s *Store) write(dir string, v archivedVersion) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal version: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create version directory: %w", err)
+	}
+	path := versionPath(dir, v.Number)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write version: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to store version: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package versions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore_RoundTrip(t *testing.T) {
+	root := t.TempDir()
+	store, err := Open(root, 0)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	for i, content := range []string{"first", "second"} {
+		v, err := store.Archive("docs", "notes/a.md", content, "overwrite")
+		if err != nil {
+			t.Fatalf("Archive failed: %v", err)
+		}
+		if v.Number != i+1 || v.Bytes != len(content) || v.SHA256 != Hash(content) {
+			t.Errorf("Unexpected version %+v for %q", v, content)
+		}
+	}
+
+	// A reopened store reads the same versions
+	reopened, err := Open(root, 0)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	versions, err := reopened.List("docs", "notes/a.md")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Number != 2 || versions[1].Number != 1 {
+		t.Fatalf("Expected versions 2 and 1, newest first, got %+v", versions)
+	}
+	v, content, err := reopened.Get("docs", "notes/a.md", 1)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if content != "first" || v.Reason != "overwrite" || v.Collection != "docs" || v.Entry != "notes/a.md" {
+		t.Errorf("Unexpected version 1: %+v with content %q", v, content)
+	}
+	if _, _, err := reopened.Get("docs", "notes/a.md", 3); err == nil {
+		t.Error("Expected a missing version to fail")
+	}
+}
+
+func TestStore_SkipsIdenticalContent(t *testing.T) {
+	store, err := Open(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	first, err := store.Archive("docs", "a.md", "same", "overwrite")
+	if err != nil {
+		t.Fatalf("Archive failed: %v", err)
+	}
+	again, err := store.Archive("docs", "a.md", "same", "delete")
+	if err != nil {
+		t.Fatalf("Archive failed: %v", err)
+	}
+	if again != first {
+		t.Errorf("Expected the existing version %+v, got %+v", first, again)
+	}
+	versions, err := store.List("docs", "a.md")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(versions) != 1 {
+		t.Errorf("Expected 1 version, got %d", len(versions))
+	}
+}
+
+func TestStore_PrunesToMaxVersions(t *testing.T) {
+	store, err := Open(t.TempDir(), 2)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	for _, content := range []string{"v1", "v2", "v3", "v4"} {
+		if _, err := store.Archive("docs", "a.md", content, "overwrite"); err != nil {
+			t.Fatalf("Archive failed: %v", err)
+		}
+	}
+
+	versions, err := store.List("docs", "a.md")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Number != 4 || versions[1].Number != 3 {
+		t.Fatalf("Expected versions 4 and 3 to be kept, got %+v", versions)
+	}
+	if _, _, err := store.Get("docs", "a.md", 1); err == nil {
+		t.Error("Expected version 1 to be pruned")
+	}
+}
+
+func TestStore_EmptyList(t *testing.T) {
+	store, err := Open(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	versions, err := store.List("docs", "never-archived.md")
+	if err != nil || len(versions) != 0 {
+		t.Errorf("Expected no versions, got %v (%v)", versions, err)
+	}
+}
+
+func TestStore_StaysInsideRoot(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "versions")
+	store, err := Open(root, 0)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	names := [][2]string{{"..", ".."}, {".", "."}, {"..", "a.md"}, {"docs", "../../escape"}, {"docs", "..."}}
+	for _, n := range names {
+		if _, err := store.Archive(n[0], n[1], n[0]+"/"+n[1], "overwrite"); err != nil {
+			t.Fatalf("Archive of %s/%s failed: %v", n[0], n[1], err)
+		}
+	}
+	// Each name keeps its own versions
+	for _, n := range names {
+		versions, err := store.List(n[0], n[1])
+		if err != nil || len(versions) != 1 {
+			t.Fatalf("Expected 1 version of %s/%s, got %v (%v)", n[0], n[1], versions, err)
+		}
+		if _, content, err := store.Get(n[0], n[1], 1); err != nil || content != n[0]+"/"+n[1] {
+			t.Errorf("Expected to read back %s/%s, got %q (%v)", n[0], n[1], content, err)
+		}
+	}
+
+	// Nothing may be written next to the store
+	files, err := os.ReadDir(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name() != "versions" {
+		t.Errorf("Expected only the store in %s, got %v", parent, files)
+	}
+}
+
+func TestStore_RejectsEmptyNames(t *testing.T) {
+	store, err := Open(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, err := store.Archive("", "a.md", "content", "overwrite"); err == nil {
+		t.Error("Expected an empty collection name to fail")
+	}
+	if _, err := store.List("docs", ""); err == nil {
+		t.Error("Expected an empty entry name to fail")
+	}
+}